@@ -0,0 +1,194 @@
+package goje
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// QueryBuilder a fluent and immutable select of a table, each method returns a copy,
+// so a base query could be shared between goroutines and extended
+//
+//	active := goje.From("users").Where("active = ?", true)
+//	query, args, err := active.Select("id", "name").OrderBy("id DESC").Limit(10).Build()
+//	users, err := goje.Fetch[User](handler, active.LeftJoin("teams", "teams.id = users.team_id"))
+type QueryBuilder struct {
+	table   string
+	columns []string
+	queries []QueryInterface
+}
+
+// From starts a query of the table
+func From(Tablename string) QueryBuilder {
+	return QueryBuilder{table: Tablename}
+}
+
+// with returns a copy of the builder with the query parts
+func (b QueryBuilder) with(queries ...QueryInterface) QueryBuilder {
+	b.queries = append(b.queries[:len(b.queries):len(b.queries)], queries...)
+	return b
+}
+
+// Select adds columns, all columns (*) are selected if there isn't any
+func (b QueryBuilder) Select(columns ...string) QueryBuilder {
+	b.columns = append(b.columns[:len(b.columns):len(b.columns)], columns...)
+	return b
+}
+
+// Apply adds query parts: subqueries, CTEs, windows ...
+func (b QueryBuilder) Apply(queries ...QueryInterface) QueryBuilder {
+	return b.with(queries...)
+}
+
+func (b QueryBuilder) Where(query string, args ...any) QueryBuilder {
+	return b.with(Where(query, args...))
+}
+
+func (b QueryBuilder) WhereIn(column string, args ...any) QueryBuilder {
+	return b.with(WhereIn(column, args...))
+}
+
+func (b QueryBuilder) WhereNotIn(column string, args ...any) QueryBuilder {
+	return b.with(WhereNotIn(column, args...))
+}
+
+// Or adds the conditions joined by OR
+func (b QueryBuilder) Or(queries ...QueryInterface) QueryBuilder {
+	return b.with(OR(queries...))
+}
+
+// Join inner joins the table
+func (b QueryBuilder) Join(table string, on string, args ...any) QueryBuilder {
+	return b.with(InnerJoin(table, on, args...))
+}
+
+func (b QueryBuilder) LeftJoin(table string, on string, args ...any) QueryBuilder {
+	return b.with(LeftJoin(table, on, args...))
+}
+
+func (b QueryBuilder) RightJoin(table string, on string, args ...any) QueryBuilder {
+	return b.with(RightJoin(table, on, args...))
+}
+
+func (b QueryBuilder) GroupBy(query string, args ...any) QueryBuilder {
+	return b.with(GroupBy(query, args...))
+}
+
+func (b QueryBuilder) Having(query string, args ...any) QueryBuilder {
+	return b.with(Having(query, args...))
+}
+
+func (b QueryBuilder) OrderBy(query string, args ...any) QueryBuilder {
+	return b.with(Order(query, args...))
+}
+
+// Limit of rows, the last one wins
+func (b QueryBuilder) Limit(limit int) QueryBuilder {
+	return b.with(Limit(limit))
+}
+
+// Offset of rows, the last one wins
+func (b QueryBuilder) Offset(offset int) QueryBuilder {
+	return b.with(Offset(offset))
+}
+
+// Table returns table of the query
+func (b QueryBuilder) Table() string {
+	return b.table
+}
+
+// Queries returns a copy of query parts, for Select, RawDelete ...
+func (b QueryBuilder) Queries() []QueryInterface {
+	return append([]QueryInterface(nil), b.queries...)
+}
+
+// Sub returns the query as a subquery in the DefaultDialect
+func (b QueryBuilder) Sub() *Subquery {
+	return Sub(b.table, b.selectColumns(nil), b.queries...)
+}
+
+// Build renders the select in the DefaultDialect
+func (b QueryBuilder) Build() (string, []any, error) {
+	return b.BuildDialect(DefaultDialect)
+}
+
+// BuildDialect renders the select in the dialect syntax
+func (b QueryBuilder) BuildDialect(dialect Dialect) (string, []any, error) {
+	return DialectSelectQueryBuilder(dialect, b.table, b.selectColumns(nil), b.queries)
+}
+
+// selectColumns returns a copy of columns (builders qoute them in place), fallback if there isn't any
+func (b QueryBuilder) selectColumns(fallback []string) []string {
+	if len(b.columns) == 0 {
+		if fallback != nil {
+			return fallback
+		}
+		return []string{"*"}
+	}
+	return append([]string(nil), b.columns...)
+}
+
+// Query runs the select on the context
+func (b QueryBuilder) Query(ctx *Context) (*Rows, error) {
+	if ctx == nil || ctx.DB == nil {
+		return nil, ErrHandlerIsNil
+	}
+	query, args, err := b.BuildDialect(ctx.GetDialect())
+	if err != nil {
+		return nil, err
+	}
+	return ctx.reader().query("Select", b.table, query, args)
+}
+
+// Delete deletes rows that match conditions of the query, like RawDelete
+func (b QueryBuilder) Delete(ctx *Context) (int64, error) {
+	return ctx.RawDelete(b.table, b.queries)
+}
+
+// Update updates rows that match conditions of the query, like RawUpdate
+func (b QueryBuilder) Update(ctx *Context, cols map[string]any) (int64, error) {
+	return ctx.RawUpdate(b.table, cols, b.queries...)
+}
+
+// Fetch runs the query and scan rows into T like Select,
+// tagged columns of T are selected if the builder hasn't any column
+func Fetch[T any](ctx *Context, b QueryBuilder) ([]T, error) {
+	if ctx == nil || ctx.DB == nil {
+		return nil, ErrHandlerIsNil
+	}
+
+	info, err := getStructInfo(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := DialectSelectQueryBuilder(ctx.GetDialect(), b.table, b.selectColumns(info.columns()), b.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ctx.reader().query("Select", b.table, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := newRowScanner(info, rows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return scanRows[T](ctx, rows, scanner)
+}
+
+// FetchOne runs the query with LIMIT 1 and scan the row into T,
+// returns sql.ErrNoRows if there isn't any row
+func FetchOne[T any](ctx *Context, b QueryBuilder) (T, error) {
+	var zero T
+	items, err := Fetch[T](ctx, b.Limit(1))
+	if err != nil {
+		return zero, err
+	}
+	if len(items) == 0 {
+		return zero, sql.ErrNoRows
+	}
+	return items[0], nil
+}
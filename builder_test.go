@@ -0,0 +1,143 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	base := From("users").Select("id", "name").Where("active = ?", true)
+	tests := []struct {
+		name     string
+		builder  QueryBuilder
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:     "base",
+			builder:  base,
+			want:     "SELECT `id`,`name`  FROM `users`  WHERE (active = ?)",
+			wantArgs: []any{true},
+		},
+		{
+			name:     "all columns",
+			builder:  From("users"),
+			want:     "SELECT *  FROM `users` ",
+			wantArgs: nil,
+		},
+		{
+			name: "full",
+			builder: base.Select("teams.title").
+				Join("teams", "teams.id = users.team_id AND teams.kind = ?", "pro").
+				WhereIn("users.role", "admin", "owner").
+				Or(Where("users.id = ?", 1), Where("users.id = ?", 2)).
+				GroupBy("users.id").
+				Having("COUNT(*) > ?", 1).
+				OrderBy("users.id DESC").
+				Limit(10).
+				Offset(20),
+			want: "SELECT `id`,`name`,`teams`.`title`  FROM `users`  INNER JOIN teams ON teams.id = users.team_id AND teams.kind = ?  " +
+				"WHERE (active = ?) AND (users.role IN(?,?)) AND (users.id = ? OR users.id = ?) GROUP BY `users`.`id` HAVING COUNT(*) > ? ORDER BY users.id DESC LIMIT ? OFFSET ?",
+			wantArgs: []any{"pro", true, "admin", "owner", 1, 2, 1, Limit(10), Offset(20)},
+		},
+		{
+			name:     "chained order",
+			builder:  base.OrderBy("name").OrderBy("FIELD(role, ?) DESC", "admin").OrderBy("id"),
+			want:     "SELECT `id`,`name`  FROM `users`  WHERE (active = ?) ORDER BY name,FIELD(role, ?) DESC,id",
+			wantArgs: []any{true, "admin"},
+		},
+		{
+			name:     "subquery",
+			builder:  base.WhereIn("id", From("orders").Select("user_id").Where("total > ?", 100).Sub()),
+			want:     "SELECT `id`,`name`  FROM `users`  WHERE (active = ?) AND (id IN (SELECT `user_id`  FROM `orders`  WHERE (total > ?)))",
+			wantArgs: []any{true, 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.builder.BuildDialect(MySQLDialect{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("query\n got %s\nwant %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestQueryBuilderImmutable(t *testing.T) {
+	base := From("users").Select("id").Where("active = ?", true)
+
+	var wg sync.WaitGroup
+	queries := make([]string, 8)
+	for i := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query, _, err := base.Select("name").Where("id = ?", i).Limit(i).Build()
+			if err != nil {
+				t.Error(err)
+			}
+			queries[i] = query
+		}()
+	}
+	wg.Wait()
+
+	want := "SELECT `id`,`name`  FROM `users`  WHERE (active = ?) AND (id = ?) LIMIT ?"
+	for _, query := range queries {
+		if query != want {
+			t.Errorf("query\n got %s\nwant %s", query, want)
+		}
+	}
+	if query, _, _ := base.Build(); query != "SELECT `id`  FROM `users`  WHERE (active = ?)" {
+		t.Errorf("base query changed: %s", query)
+	}
+}
+
+func TestQueryBuilderExecute(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if len(args) > 0 && args[0] == int64(404) {
+			return []string{"id", "name"}, nil, nil
+		}
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "john"}, {int64(2), "jane"}}, nil
+	}
+	handler := MakeHandlerDB(context.Background(), db)
+	users := From("users").Where("team_id = ?", 7)
+
+	items, err := Fetch[testUser](handler, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Name != "john" {
+		t.Errorf("Fetch() = %+v", items)
+	}
+	want := "SELECT `id`,`name`,`nickname`,`email`,`created_at`  FROM `users`  WHERE (team_id = ?)"
+	if log := fake.Log(); log[len(log)-1] != want {
+		t.Errorf("query\n got %s\nwant %s", log[len(log)-1], want)
+	}
+
+	if _, err := FetchOne[testUser](handler, From("users").Where("id = ?", 404)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FetchOne() error = %v, want %v", err, sql.ErrNoRows)
+	}
+
+	if _, err := users.Delete(handler); err != nil {
+		t.Fatal(err)
+	}
+	if log := fake.Log(); log[len(log)-1] != "DELETE FROM `users`  WHERE (team_id = ?)" {
+		t.Errorf("Delete() query = %s", log[len(log)-1])
+	}
+
+	if _, err := Fetch[testUser](nil, users); !errors.Is(err, ErrHandlerIsNil) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrHandlerIsNil)
+	}
+}
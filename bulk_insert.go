@@ -4,35 +4,62 @@ import (
 	"reflect"
 )
 
+// bulkGroup rows of a table that insert by one statement,
+// rows with explicit autoincrement values and rows without them have different columns
+type bulkGroup struct {
+	table    string
+	explicit bool
+}
+
 // BulkInsert insert multiple mixed items, INSERT [INTO,IGNORE]
+// a BeforeInsert hook error aborts the whole insert, AfterInsert hooks are called per inserted table
+// zero autoincrement columns are generated by database, rows that set them are inserted by a separate statement
 func BulkInsert(ctx *Context, ignore bool, entities []Entity) (int64, []error) {
 
-	// rows: [table_name][column_name]value
-	rows := map[string][]map[string]any{}
+	// rows: [group][column_name]value
+	rows := map[bulkGroup][]map[string]any{}
+	// inserting: [group]entities
+	inserting := map[bulkGroup][]Entity{}
+	// groups in order of entities
+	var groups []bulkGroup
+	var errorList []error
 
 	for _, entity := range entities {
-		if _, ok := rows[entity.GetTableName()]; !ok {
-			rows[entity.GetTableName()] = []map[string]any{}
+		if err := runBeforeInsert(ctx, entity); err != nil {
+			return 0, []error{err}
 		}
 
-		v := reflect.ValueOf(entity)
-		t := reflect.TypeOf(entity)
-		if t.Len() == 0 {
+		v := reflect.Indirect(reflect.ValueOf(entity))
+		info, err := getStructInfo(v.Type())
+		if err != nil {
+			errorList = append(errorList, err)
 			continue
 		}
 
-		currentItem := make(map[string]any, t.Len())
+		currentItem := make(map[string]any, len(info.fields))
+		group := bulkGroup{table: entity.GetTableName()}
 
-		// loop over struct fields and compare `db` tag
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			dbTag := field.Tag.Get("db")
-			if dbTag != "-" && dbTag != "" {
-				currentItem[dbTag] = v.Field(i).Interface()
+		// loop over `db` tagged fields, zero autoincrement columns are generated by database
+		for _, field := range info.fields {
+			value, ok := fieldValue(v, field.index)
+			if !ok {
+				currentItem[field.column] = nil
+				continue
 			}
+			if field.hasOption(TagAutoIncrement) {
+				if value.IsZero() {
+					continue
+				}
+				group.explicit = true
+			}
+			currentItem[field.column] = value.Interface()
 		}
 
-		rows[entity.GetTableName()] = append(rows[entity.GetTableName()], currentItem)
+		if _, ok := rows[group]; !ok {
+			groups = append(groups, group)
+		}
+		rows[group] = append(rows[group], currentItem)
+		inserting[group] = append(inserting[group], entity)
 	}
 
 	if len(rows) == 0 {
@@ -40,15 +67,19 @@ func BulkInsert(ctx *Context, ignore bool, entities []Entity) (int64, []error) {
 	}
 
 	var inserted int64
-	var errorList []error
-	for table, items := range rows {
-		if len(items) == 0 {
+	for _, group := range groups {
+		r, err := RawBulkInsert(ctx, ignore, group.table, rows[group])
+		inserted += r
+		errorList = append(errorList, err)
+		if err != nil {
 			continue
 		}
 
-		r, err := RawBulkInsert(ctx, ignore, table, items)
-		inserted += r
-		errorList = append(errorList, err)
+		for _, entity := range inserting[group] {
+			if err := runAfterInsert(ctx, entity); err != nil {
+				return inserted, append(errorList, err)
+			}
+		}
 	}
 
 	return inserted, errorList
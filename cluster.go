@@ -0,0 +1,224 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Balance strategies of replicas
+const (
+	BalanceRoundRobin = "round-robin"
+	BalanceLeastConn  = "least-conn"
+)
+
+// ClusterConfig one primary and replicas of a database
+//
+//	# yaml example
+//	primary:
+//	  driver: mysql
+//	  host: db-primary
+//	  schema: mydbname
+//	replicas:
+//	  - driver: mysql
+//	    host: db-replica-1
+//	    schema: mydbname
+//	balance: least-conn
+type ClusterConfig struct {
+	Primary  DBConfig   `json:"primary" yaml:"primary"`
+	Replicas []DBConfig `json:"replicas" yaml:"replicas"`
+	// Balance of reads between replicas: BalanceRoundRobin (default) or BalanceLeastConn
+	Balance string `json:"balance" yaml:"balance"`
+}
+
+// Cluster connections of the primary and replicas, handlers of the primary read from replicas:
+// Select, Get, Iterate and Load of non-transactional contexts go to a replica,
+// writes, raw queries and transactions go to the primary, Context.UsePrimary forces it for reads too.
+// Replicas that fail CheckReplicas are skipped until they pass it again
+type Cluster struct {
+	Primary  *sql.DB
+	Replicas []*sql.DB
+	balance  string
+	next     atomic.Uint64
+
+	mu sync.RWMutex
+	// down: unhealthy replicas
+	down map[*sql.DB]struct{}
+}
+
+// NewCluster connects to the primary and replicas of the config
+func NewCluster(conf *ClusterConfig) (*Cluster, error) {
+	switch conf.Balance {
+	case "", BalanceRoundRobin, BalanceLeastConn:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBalance, conf.Balance)
+	}
+
+	// connections share the schema, name them by their role for metrics and PoolStats
+	primaryConf := conf.Primary
+	if primaryConf.Name == "" {
+		primaryConf.Name = primaryConf.name() + "/primary"
+	}
+	primary, err := NewDBConnection(&primaryConf)
+	if err != nil {
+		return nil, err
+	}
+	cluster := &Cluster{Primary: primary, balance: conf.Balance}
+
+	for i := range conf.Replicas {
+		replicaConf := conf.Replicas[i]
+		if replicaConf.Name == "" {
+			replicaConf.Name = fmt.Sprintf("%s/replica-%d", replicaConf.name(), i+1)
+		}
+		replica, err := NewDBConnection(&replicaConf)
+		if err != nil {
+			return nil, errors.Join(err, cluster.Close())
+		}
+		cluster.Replicas = append(cluster.Replicas, replica)
+	}
+
+	h := handleFor(primary)
+	h.mu.Lock()
+	h.cluster = cluster
+	h.mu.Unlock()
+	return cluster, nil
+}
+
+// InitCluster connects to the cluster and sets its primary as the default database
+func InitCluster(conf *ClusterConfig) (*Cluster, error) {
+	cluster, err := NewCluster(conf)
+	if err != nil {
+		return nil, err
+	}
+	DefatultDB = cluster.Primary
+	DefaultDialect = dialectOf(cluster.Primary)
+	return cluster, nil
+}
+
+// Replica returns a healthy replica by the balance strategy, the primary if there isn't any
+func (c *Cluster) Replica() *sql.DB {
+	replicas := c.HealthyReplicas()
+	if len(replicas) == 0 {
+		return c.Primary
+	}
+
+	if c.balance == BalanceLeastConn {
+		best, inUse := replicas[0], -1
+		for _, replica := range replicas {
+			if n := replica.Stats().InUse; inUse < 0 || n < inUse {
+				best, inUse = replica, n
+			}
+		}
+		return best
+	}
+
+	return replicas[(c.next.Add(1)-1)%uint64(len(replicas))]
+}
+
+// HealthyReplicas returns replicas that didn't fail the last CheckReplicas
+func (c *Cluster) HealthyReplicas() []*sql.DB {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.down) == 0 {
+		return c.Replicas
+	}
+
+	out := make([]*sql.DB, 0, len(c.Replicas))
+	for _, replica := range c.Replicas {
+		if _, down := c.down[replica]; !down {
+			out = append(out, replica)
+		}
+	}
+	return out
+}
+
+// CheckReplicas pings replicas, marks failed ones unhealthy and re-admits recovered ones,
+// returns errors of the failed replicas
+func (c *Cluster) CheckReplicas(ctx context.Context) error {
+	errs := make([]error, len(c.Replicas))
+	var wg sync.WaitGroup
+	for i, replica := range c.Replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = replica.PingContext(ctx)
+		}()
+	}
+	wg.Wait()
+
+	logger := slog.Default()
+	if h := handleOf(c.Primary); h != nil {
+		h.mu.RLock()
+		if h.log.Logger != nil {
+			logger = h.log.Logger
+		}
+		h.mu.RUnlock()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var failed []error
+	for i, replica := range c.Replicas {
+		_, wasDown := c.down[replica]
+		if errs[i] == nil {
+			if wasDown {
+				delete(c.down, replica)
+				logger.Info("replica is healthy again", slog.Int("replica", i))
+			}
+			continue
+		}
+
+		if c.down == nil {
+			c.down = map[*sql.DB]struct{}{}
+		}
+		c.down[replica] = struct{}{}
+		if !wasDown {
+			logger.Warn("replica is unhealthy", slog.Int("replica", i), slog.String("error", errs[i].Error()))
+		}
+		failed = append(failed, fmt.Errorf("replica %d: %w", i, errs[i]))
+	}
+	return errors.Join(failed...)
+}
+
+// Close closes the primary and replicas
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, db := range append([]*sql.DB{c.Primary}, c.Replicas...) {
+		if db != nil {
+			errs = append(errs, CloseDB(db))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UsePrimary returns a copy of the context that reads from the primary, for read after write consistency
+func (c *Context) UsePrimary() *Context {
+	out := *c
+	out.cluster = nil
+	return &out
+}
+
+// reader returns the context that runs reads: a copy on a replica if the context belongs to a cluster
+func (c *Context) reader() *Context {
+	if c.Tx || c.cluster == nil {
+		return c
+	}
+	replica := c.cluster.Replica()
+	if replica == c.cluster.Primary {
+		return c
+	}
+
+	out := *c
+	out.DB = replica
+	// metrics of the read are reported by the name of the replica
+	if h := handleOf(replica); h != nil {
+		h.mu.RLock()
+		out.dbName = h.name
+		h.mu.RUnlock()
+	}
+	return &out
+}
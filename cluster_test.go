@@ -0,0 +1,87 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func testCluster(t *testing.T, balance string) (*Cluster, []*fakeDB) {
+	primary, primaryFake := newFakeDB(t)
+	replica1, fake1 := newFakeDB(t)
+	replica2, fake2 := newFakeDB(t)
+
+	cluster := &Cluster{Primary: primary, Replicas: []*sql.DB{replica1, replica2}, balance: balance}
+	handleFor(primary).cluster = cluster
+	handleFor(primary).name = "shop/primary"
+	handleFor(replica1).name = "shop/replica-1"
+	handleFor(replica2).name = "shop/replica-2"
+	return cluster, []*fakeDB{primaryFake, fake1, fake2}
+}
+
+func TestClusterRouting(t *testing.T) {
+	cluster, fakes := testCluster(t, BalanceRoundRobin)
+	handler := MakeHandlerDB(context.Background(), cluster.Primary)
+
+	collector := &testCollector{}
+	SetMetricsCollector(collector)
+	defer SetMetricsCollector(nil)
+
+	for range 4 {
+		if _, err := Select[testUser](handler, "users"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := handler.RawDelete("users", []QueryInterface{Where("id = ?", 1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Select[testUser](handler.UsePrimary(), "users"); err != nil {
+		t.Fatal(err)
+	}
+	err := WithTxDB(context.Background(), cluster.Primary, nil, func(tx *Context) error {
+		_, err := Select[testUser](tx, "users")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := []int{len(fakes[0].Log()), len(fakes[1].Log()), len(fakes[2].Log())}
+	// primary: delete, forced select, BEGIN, select, COMMIT
+	if counts[0] != 5 || counts[1] != 2 || counts[2] != 2 {
+		t.Errorf("queries of primary, replica1, replica2 = %v, want [5 2 2]", counts)
+	}
+
+	// reads are reported by the replica that runs them, BEGIN and COMMIT aren't queries of the middlewares
+	databases := map[string]int{}
+	for _, metric := range collector.metrics {
+		databases[metric.Database]++
+	}
+	if databases["shop/primary"] != 3 || databases["shop/replica-1"] != 2 || databases["shop/replica-2"] != 2 {
+		t.Errorf("query metrics by database = %v", databases)
+	}
+}
+
+func TestClusterLeastConn(t *testing.T) {
+	cluster, _ := testCluster(t, BalanceLeastConn)
+
+	// a running query holds a connection of the first replica
+	rows, err := cluster.Replicas[0].Query("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	for range 3 {
+		if got := cluster.Replica(); got != cluster.Replicas[1] {
+			t.Fatalf("Replica() isn't the least used one")
+		}
+	}
+}
+
+func TestNewClusterBalance(t *testing.T) {
+	if _, err := NewCluster(&ClusterConfig{Balance: "random"}); !errors.Is(err, ErrUnknownBalance) {
+		t.Errorf("NewCluster() error = %v, want %v", err, ErrUnknownBalance)
+	}
+}
@@ -0,0 +1,153 @@
+// Command goje is the command line tool of goje
+//
+//	goje gen -ddl schema.sql -out ./models -package models
+//	goje gen -host 127.0.0.1 -user root -schema mydb -out ./models
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/genigo/goje"
+	"github.com/genigo/goje/gen"
+)
+
+const usage = `goje is the command line tool of goje
+
+Usage:
+
+	goje gen [flags]    generate entities from a live database or a DDL dump
+
+Run "goje gen -h" for the flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "gen":
+		if err := runGen(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "goje gen:", err)
+			os.Exit(1)
+		}
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "goje: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// isFlagSet check the flag is passed in the command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	ddl := flags.String("ddl", "", "read the schema from a SQL DDL dump file instead of a live database")
+	out := flags.String("out", "./models", "output directory")
+	pkg := flags.String("package", "", "package name of generated files (default: output directory name)")
+	tables := flags.String("tables", "", "comma separated tables to generate (default: all)")
+	null := flags.String("null", gen.NullSQL, "nullable columns style: sql (sql.Null*) or pointer (*T)")
+	dbName := flags.String("db", "", "name of the registered database (goje.RegisterDB) of entities")
+
+	conn := &goje.DBConfig{}
+	flags.StringVar(&conn.Driver, "driver", "mysql", "database driver of live schema")
+	flags.StringVar(&conn.Host, "host", "127.0.0.1", "database host")
+	flags.IntVar(&conn.Port, "port", 3306, "database port")
+	flags.StringVar(&conn.User, "user", "root", "database user")
+	// the default isn't the env value, flag prints non empty defaults in the usage
+	flags.StringVar(&conn.Password, "password", "", "database password (default: $GOJE_PASSWORD)")
+	flags.StringVar(&conn.Schema, "schema", "", "database schema to read")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !isFlagSet(flags, "password") {
+		conn.Password = os.Getenv("GOJE_PASSWORD")
+	}
+
+	schema, err := readSchema(*ddl, conn)
+	if err != nil {
+		return err
+	}
+
+	if *pkg == "" {
+		abs, err := filepath.Abs(*out)
+		if err != nil {
+			return err
+		}
+		*pkg = strings.ReplaceAll(filepath.Base(abs), "-", "_")
+	}
+
+	opts := gen.Options{
+		Package: *pkg,
+		Null:    *null,
+		DBName:  *dbName,
+	}
+	if *tables != "" {
+		opts.Tables = strings.Split(*tables, ",")
+	}
+
+	files, err := gen.Generate(schema, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(*out, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// readSchema reads the schema from the DDL file if it's set, otherwise from the live database
+func readSchema(ddl string, conn *goje.DBConfig) (*gen.Schema, error) {
+	if ddl != "" {
+		content, err := os.ReadFile(ddl)
+		if err != nil {
+			return nil, err
+		}
+		return gen.ParseDDL(string(content))
+	}
+
+	if conn.Schema == "" {
+		return nil, fmt.Errorf("either -ddl or -schema is required")
+	}
+
+	db, err := goje.NewDBConnection(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return gen.LoadSchema(goje.MakeHandlerDB(context.Background(), db), conn.Schema)
+}
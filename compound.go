@@ -0,0 +1,119 @@
+package goje
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Operators of compound queries
+const (
+	Union     string = "UNION"
+	UnionAll  string = "UNION ALL"
+	Intersect string = "INTERSECT"
+	Except    string = "EXCEPT"
+)
+
+// SelectSpec inputs of SelectQueryBuilder for a select of a compound query
+type SelectSpec struct {
+	Table   string
+	Columns []string
+	Queries []QueryInterface
+}
+
+// CompoundQueryBuilder combines selects by the operator (Union, UnionAll, Intersect, Except),
+// Queries (Order, Limit, Offset) apply to the whole compound, args are merged in order
+//
+//	query, args, err := goje.CompoundQueryBuilder(goje.UnionAll, []goje.SelectSpec{
+//		{Table: "posts", Columns: []string{"id", "created_at", "'post' AS kind"}, Queries: []goje.QueryInterface{goje.Where("user_id = ?", id)}},
+//		{Table: "comments", Columns: []string{"id", "created_at", "'comment' AS kind"}, Queries: []goje.QueryInterface{goje.Where("user_id = ?", id)}},
+//	}, []goje.QueryInterface{goje.Order("created_at DESC"), goje.Limit(20)})
+func CompoundQueryBuilder(Operator string, Selects []SelectSpec, Queries []QueryInterface) (string, []any, error) {
+	return DialectCompoundQueryBuilder(DefaultDialect, Operator, Selects, Queries)
+}
+
+// DialectCompoundQueryBuilder combines selects by the operator in the dialect syntax
+// selects can't have ORDER BY/LIMIT/OFFSET/WITH, they would apply to the whole compound
+// and sqlite dosen't support parentheses around them, use Queries of the compound or FromSub instead
+func DialectCompoundQueryBuilder(dialect Dialect, Operator string, Selects []SelectSpec, Queries []QueryInterface) (string, []any, error) {
+	switch Operator {
+	case Union, UnionAll, Intersect, Except:
+	default:
+		return "", nil, errors.New("this function dosen't support: " + Operator)
+	}
+	if len(Selects) < 2 {
+		return "", nil, ErrNoCompoundSelects
+	}
+
+	for _, q := range Queries {
+		switch q.GetType() {
+		case QueryTypeOrder, QueryTypeLimit, QueryTypeOffset:
+		default:
+			return "", nil, errors.New("compound query dosen't support: " + q.GetType())
+		}
+	}
+
+	var parts []string
+	var args []any
+	for _, s := range Selects {
+		for _, q := range s.Queries {
+			switch q.GetType() {
+			case QueryTypeOrder, QueryTypeLimit, QueryTypeOffset, QueryTypeWith:
+				return "", nil, errors.New("select of a compound query dosen't support: " + q.GetType())
+			}
+		}
+		// columnsFilter qoutes columns in place, keep columns of the caller
+		query, sargs, err := argumentLessQuery(dialect, ActionSelect, s.Table, append([]string(nil), s.Columns...), s.Queries)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, query)
+		args = append(args, sargs...)
+	}
+
+	conditions, cargs, err := DialectSQLConditionBuilder(dialect, Queries)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := strings.Join(parts, " "+Operator+" ") + conditions
+	return Rebind(dialect, query), append(args, cargs...), nil
+}
+
+// SelectCompound runs the compound query and scan rows into T like Select,
+// selects without Columns select tagged columns of T
+func SelectCompound[T any](ctx *Context, Operator string, Selects []SelectSpec, Queries ...QueryInterface) ([]T, error) {
+	if ctx == nil || ctx.DB == nil {
+		return nil, ErrHandlerIsNil
+	}
+
+	info, err := getStructInfo(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	selects := make([]SelectSpec, len(Selects))
+	for i, s := range Selects {
+		if len(s.Columns) == 0 {
+			s.Columns = info.columns()
+		}
+		selects[i] = s
+	}
+
+	query, args, err := DialectCompoundQueryBuilder(ctx.GetDialect(), Operator, selects, Queries)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ctx.reader().query("SelectCompound", "", query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := newRowScanner(info, rows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return scanRows[T](ctx, rows, scanner)
+}
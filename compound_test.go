@@ -0,0 +1,124 @@
+package goje
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompoundQueryBuilder(t *testing.T) {
+	feed := []SelectSpec{
+		{Table: "posts", Columns: []string{"id", "created_at"}, Queries: []QueryInterface{Where("user_id = ?", 1)}},
+		{Table: "comments", Columns: []string{"id", "created_at"}, Queries: []QueryInterface{Where("user_id = ?", 2), WhereIn("post_id", 3, 4)}},
+	}
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		operator string
+		selects  []SelectSpec
+		queries  []QueryInterface
+		want     string
+		wantArgs []any
+		wantErr  error
+	}{
+		{
+			name:     "union all with outer order and limit",
+			dialect:  MySQLDialect{},
+			operator: UnionAll,
+			selects:  feed,
+			queries:  []QueryInterface{Order("created_at DESC"), Limit(20), Offset(40)},
+			want:     "SELECT `id`,`created_at`  FROM `posts`  WHERE (user_id = ?) UNION ALL SELECT `id`,`created_at`  FROM `comments`  WHERE (user_id = ?) AND (post_id IN(?,?))  ORDER BY created_at DESC LIMIT ? OFFSET ?",
+			wantArgs: []any{1, 2, 3, 4, Limit(20), Offset(40)},
+		},
+		{
+			name:     "except in postgres",
+			dialect:  PostgresDialect{},
+			operator: Except,
+			selects:  feed,
+			want:     `SELECT "id","created_at"  FROM "posts"  WHERE (user_id = $1) EXCEPT SELECT "id","created_at"  FROM "comments"  WHERE (user_id = $2) AND (post_id IN($3,$4)) `,
+			wantArgs: []any{1, 2, 3, 4},
+		},
+		{
+			name:     "unknown operator",
+			operator: "MINUS",
+			selects:  feed,
+			wantErr:  errors.New("this function dosen't support: MINUS"),
+		},
+		{
+			name:     "one select",
+			operator: Union,
+			selects:  feed[:1],
+			wantErr:  ErrNoCompoundSelects,
+		},
+		{
+			name:     "where of the compound",
+			operator: Intersect,
+			selects:  feed,
+			queries:  []QueryInterface{Where("id = ?", 1)},
+			wantErr:  errors.New("compound query dosen't support: where"),
+		},
+		{
+			name:     "limit of a select",
+			operator: UnionAll,
+			selects:  []SelectSpec{feed[0], {Table: "comments", Columns: []string{"id", "created_at"}, Queries: []QueryInterface{Order("created_at DESC"), Limit(5)}}},
+			wantErr:  errors.New("select of a compound query dosen't support: order"),
+		},
+		{
+			name:     "with of a select",
+			operator: Union,
+			selects:  []SelectSpec{{Table: "recent", Columns: []string{"id"}, Queries: []QueryInterface{With("recent", Sub("posts", []string{"id"}))}}, feed[1]},
+			wantErr:  errors.New("select of a compound query dosen't support: with"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := DialectCompoundQueryBuilder(tt.dialect, tt.operator, tt.selects, tt.queries)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("query\n got %s\nwant %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+	if feed[0].Columns[0] != "id" {
+		t.Errorf("columns of the select changed: %v", feed[0].Columns)
+	}
+}
+
+func TestSelectCompound(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "john"}, {int64(2), "jane"}}, nil
+	}
+
+	users, err := SelectCompound[testUser](MakeHandlerDB(context.Background(), db), Union, []SelectSpec{
+		{Table: "users", Columns: []string{"id", "name"}},
+		{Table: "admins", Columns: []string{"id", "name"}},
+	}, Order("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].Name != "jane" {
+		t.Errorf("SelectCompound() = %+v", users)
+	}
+
+	if _, err := SelectCompound[testUser](MakeHandlerDB(context.Background(), db), Union, []SelectSpec{{Table: "users"}, {Table: "admins"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT `id`,`name`,`nickname`,`email`,`created_at`  FROM `users`  UNION SELECT `id`,`name`,`nickname`,`email`,`created_at`  FROM `admins`  "
+	if log := fake.Log(); log[len(log)-1] != want {
+		t.Errorf("query\n got %s\nwant %s", log[len(log)-1], want)
+	}
+}
@@ -3,6 +3,7 @@ package goje
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"time"
 )
 
@@ -15,20 +16,43 @@ port: 3306
 user: root
 password:
 schema: mydbname
+
+# sqlite yaml example
+driver: sqlite
+path: ./data.db # or :memory:
 */
 type DBConfig struct {
+	// Name of the connection in metrics, Schema (or Path) if it's empty
+	Name     string            `json:"name" yaml:"name"`
 	Driver   string            `json:"driver" yaml:"driver"`
 	Host     string            `json:"host" yaml:"host"`
 	Port     int               `json:"port" yaml:"port"`
 	User     string            `json:"user" yaml:"user"`
 	Password string            `json:"password" yaml:"password"`
 	Schema   string            `json:"schema" yaml:"schema"`
+	Path     string            `json:"path" yaml:"path"`
 	Flags    map[string]string `json:"flags" yaml:"flags"`
 
 	MaxIdleTime     time.Duration `json:"MaxIdleTime" yaml:"MaxIdleTime"`
 	MaxOpenConns    int           `json:"MaxOpenConns" yaml:"MaxOpenConns"`
 	MaxIdleConns    int           `json:"MaxIdleConns" yaml:"MaxIdleConns"`
 	ConnMaxLifetime time.Duration `json:"ConnMaxLifetime" yaml:"ConnMaxLifetime"`
+
+	// Log logging of queries of the connection
+	Log LogConfig `json:"log" yaml:"log"`
+}
+
+// name returns Name of the connection, Schema or Path if it isn't set
+func (db DBConfig) name() string {
+	switch {
+	case db.Name != "":
+		return db.Name
+	case db.Schema != "":
+		return db.Schema
+	case db.Path != "":
+		return filepath.Base(db.Path)
+	}
+	return db.Driver
 }
 
 func (db DBConfig) String() string {
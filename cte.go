@@ -0,0 +1,108 @@
+package goje
+
+import (
+	"errors"
+	"strings"
+)
+
+const QueryTypeWith = "with"
+
+/**
+	Common Table Expression
+**/
+
+// QueryWith a common table expression, SelectQueryBuilder renders it ahead of SELECT
+type QueryWith struct {
+	name      string
+	anchor    *Subquery
+	recursive *Subquery
+}
+
+func (q QueryWith) GetType() string {
+	return QueryTypeWith
+}
+
+// GetQuery returns `name` AS (...), the recursive member joins to the anchor by UNION ALL
+func (q QueryWith) GetQuery() string {
+	body := q.anchor.query
+	if q.recursive != nil {
+		body += " UNION ALL " + q.recursive.query
+	}
+	return qouteColumn(q.name) + " AS (" + body + ")"
+}
+
+func (q QueryWith) GetArgs() []any {
+	if q.recursive == nil {
+		return q.anchor.GetArgs()
+	}
+	return append(append([]any{}, q.anchor.GetArgs()...), q.recursive.GetArgs()...)
+}
+
+func (q QueryWith) isRecursive() bool {
+	return q.recursive != nil
+}
+
+func (q QueryWith) subqueryErr() error {
+	if err := q.anchor.Err(); err != nil {
+		return err
+	}
+	if q.recursive != nil {
+		return q.recursive.Err()
+	}
+	return nil
+}
+
+// With names the subquery for the select
+//
+//	goje.SelectQueryBuilder("recent", []string{"*"}, []goje.QueryInterface{
+//		goje.With("recent", goje.Sub("orders", []string{"*"}, goje.Where("created_at > ?", since))),
+//	})
+func With(name string, sub *Subquery) QueryWith {
+	return QueryWith{name: name, anchor: sub}
+}
+
+// WithRecursive names anchor UNION ALL recursive for the select, the recursive member selects from name,
+// name could have columns: "tree(id, parent_id, depth)"
+//
+//	goje.WithRecursive("tree",
+//		goje.Sub("categories", []string{"id", "parent_id"}, goje.Where("id = ?", rootID)),
+//		goje.Sub("categories c", []string{"c.id", "c.parent_id"}, goje.InnerJoin("tree", "c.parent_id = tree.id")),
+//	)
+func WithRecursive(name string, anchor, recursive *Subquery) QueryWith {
+	return QueryWith{name: name, anchor: anchor, recursive: recursive}
+}
+
+// withClause renders CTEs of the queries: WITH [RECURSIVE] a AS (...),b AS (...)
+func withClause(Queries []QueryInterface) (string, []any) {
+	var ctes []string
+	var args []any
+	recursive := false
+	for _, q := range Queries {
+		if q.GetType() == QueryTypeWith {
+			ctes = append(ctes, q.GetQuery())
+			args = append(args, q.GetArgs()...)
+			if r, ok := q.(interface{ isRecursive() bool }); ok && r.isRecursive() {
+				recursive = true
+			}
+		}
+	}
+	if len(ctes) == 0 {
+		return "", nil
+	}
+
+	with := "WITH "
+	if recursive {
+		with += "RECURSIVE "
+	}
+	return with + strings.Join(ctes, ",") + " ", args
+}
+
+// rejectWith returns an error if the queries have a CTE, only SELECT renders them
+func rejectWith(Action string, Queries []QueryInterface) error {
+	for _, q := range Queries {
+		if q.GetType() == QueryTypeWith {
+			return errors.New(strings.ToLower(Action) + " query dosen't support: " + QueryTypeWith)
+		}
+	}
+	return nil
+}
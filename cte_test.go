@@ -0,0 +1,108 @@
+package goje
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		table    string
+		columns  []string
+		queries  []QueryInterface
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:    "with",
+			dialect: MySQLDialect{},
+			table:   "recent",
+			columns: []string{"user_id"},
+			queries: []QueryInterface{
+				Where("total > ?", 10),
+				With("recent", Sub("orders", []string{"user_id", "total"}, Where("created_at > ?", "2024-01-01"))),
+				With("vip", Sub("users", []string{"id"}, Where("level = ?", 3))),
+			},
+			want:     "WITH `recent` AS (SELECT `user_id`,`total`  FROM `orders`  WHERE (created_at > ?)),`vip` AS (SELECT `id`  FROM `users`  WHERE (level = ?)) SELECT `user_id`  FROM `recent`  WHERE (total > ?)",
+			wantArgs: []any{"2024-01-01", 3, 10},
+		},
+		{
+			name:    "recursive tree",
+			dialect: PostgresDialect{},
+			table:   "tree",
+			columns: []string{"id", "depth"},
+			queries: []QueryInterface{
+				WithRecursive("tree(id, parent_id, depth)",
+					Sub("categories", []string{"id", "parent_id", "0 AS depth"}, Where("id = ?", 7)),
+					Sub("categories c", []string{"c.id", "c.parent_id", "tree.depth + 1"}, InnerJoin("tree", "c.parent_id = tree.id"), Where("tree.depth < ?", 5)),
+				),
+				Order("depth"),
+			},
+			want: `WITH RECURSIVE tree(id, parent_id, depth) AS (SELECT "id","parent_id",0 AS depth  FROM "categories"  WHERE (id = $1) UNION ALL ` +
+				`SELECT "c"."id","c"."parent_id",tree.depth + 1  FROM categories c  INNER JOIN tree ON c.parent_id = tree.id  WHERE (tree.depth < $2)) ` +
+				`SELECT "id","depth"  FROM "tree"  ORDER BY depth`,
+			wantArgs: []any{7, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := DialectSelectQueryBuilder(tt.dialect, tt.table, tt.columns, tt.queries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("query\n got %s\nwant %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestWithErr(t *testing.T) {
+	broken := Sub("categories", []string{"id"}, Where("id = ?"))
+	_, _, err := SelectQueryBuilder("tree", []string{"id"}, []QueryInterface{WithRecursive("tree", Sub("categories", []string{"id"}), broken)})
+	if !errors.Is(err, broken.Err()) {
+		t.Errorf("SelectQueryBuilder() error = %v, want %v", err, broken.Err())
+	}
+}
+
+// testCTE a custom common table expression
+type testCTE struct{}
+
+func (testCTE) GetType() string  { return QueryTypeWith }
+func (testCTE) GetQuery() string { return "`ids` AS (SELECT 1)" }
+func (testCTE) GetArgs() []any   { return nil }
+
+func TestWithCustomAndDelete(t *testing.T) {
+	query, _, err := SelectQueryBuilder("ids", []string{"*"}, []QueryInterface{testCTE{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "WITH `ids` AS (SELECT 1) SELECT *  FROM `ids` "; query != want {
+		t.Errorf("SelectQueryBuilder() = %q, want %q", query, want)
+	}
+
+	db, fake := newFakeDB(t)
+	handler := MakeHandlerDB(context.Background(), db)
+	recent := With("recent", Sub("orders", []string{"id"}, Where("created_at > ?", "2024-01-01")))
+	want := errors.New("delete query dosen't support: with")
+
+	if _, err := handler.RawDelete("orders", []QueryInterface{recent, Where("id IN (SELECT id FROM recent)")}); err == nil || err.Error() != want.Error() {
+		t.Errorf("RawDelete() error = %v, want %v", err, want)
+	}
+	if _, err := From("orders").Apply(recent).Delete(handler); err == nil || err.Error() != want.Error() {
+		t.Errorf("QueryBuilder.Delete() error = %v, want %v", err, want)
+	}
+	if _, err := handler.RawUpdate("orders", map[string]any{"archived": 1}, recent); err == nil || err.Error() != "update query dosen't support: with" {
+		t.Errorf("RawUpdate() error = %v", err)
+	}
+	if len(fake.Log()) != 0 {
+		t.Errorf("queries with a dropped CTE ran: %v", fake.Log())
+	}
+}
@@ -0,0 +1,346 @@
+package goje
+
+import (
+	"database/sql"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect describes the SQL flavour of a database engine.
+// Goje builds every query in a neutral form (backtick qouted identifiers and `?` binds)
+// and Rebind rewrites it into the native syntax of the dialect right before execution.
+type Dialect interface {
+	// Name of the dialect: mysql, postgres, ...
+	Name() string
+	// DSN makes the data source name of sql.Open from the config
+	DSN(conn *DBConfig) (string, error)
+	// QuoteIdent qoutes a single identifier part (table, column or alias)
+	QuoteIdent(name string) string
+	// Placeholder returns the bind parameter of the n-th argument, n starts from 1
+	Placeholder(n int) string
+	// LimitOffset renders LIMIT/OFFSET clause with neutral `?` binds
+	LimitOffset(hasLimit, hasOffset bool) string
+	// InsertVerb returns the leading keywords of an insert statement
+	InsertVerb(ignore bool) string
+	// InsertSuffix returns the trailing clause of an insert statement
+	InsertSuffix(ignore bool) string
+	// InsertReturning returns the clause that returns the generated column of an insert,
+	// empty if the driver supports sql.Result.LastInsertId
+	InsertReturning(column string) string
+}
+
+// DefaultDialect is used by the package level query builders, InitDB sets it by the driver
+var DefaultDialect Dialect = MySQLDialect{}
+
+var (
+	dialectsMu sync.RWMutex
+	// dialects: [driver name]Dialect
+	dialects = map[string]Dialect{
+		"mysql":    MySQLDialect{},
+		"postgres": PostgresDialect{},
+		"pgx":      PostgresDialect{},
+		"sqlite":   SQLiteDialect{},
+		"sqlite3":  SQLiteDialect{},
+	}
+)
+
+// RegisterDialect register (or replace) dialect of a database/sql driver name
+func RegisterDialect(driver string, dialect Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[driver] = dialect
+}
+
+// GetDialect returns registered dialect of a database/sql driver name
+func GetDialect(driver string) (Dialect, error) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	dialect, ok := dialects[driver]
+	if !ok {
+		return nil, ErrUnknownDBDriver
+	}
+	return dialect, nil
+}
+
+// dialectOf returns dialect of connections that opened by NewDBConnection
+func dialectOf(db *sql.DB) Dialect {
+	if h := handleOf(db); h != nil {
+		return h.dialect
+	}
+	return nil
+}
+
+// Rebind rewrites a neutral query (backtick identifiers, `?` binds) to the dialect syntax
+// string literals are copied without any change, `??` is written as a literal `?`
+func Rebind(dialect Dialect, query string) string {
+	if dialect == nil {
+		return query
+	}
+
+	var out strings.Builder
+	out.Grow(len(query) + 16)
+
+	n := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '\'', '"':
+			end := skipQuoted(query, i)
+			out.WriteString(query[i:end])
+			i = end - 1
+		case '`':
+			end := skipQuoted(query, i)
+			name := query[i+1 : end]
+			if strings.HasSuffix(name, "`") {
+				name = name[:len(name)-1]
+			}
+			out.WriteString(dialect.QuoteIdent(strings.ReplaceAll(name, "``", "`")))
+			i = end - 1
+		case '?':
+			// `??` is an escaped `?`, e.g. jsonb operators ?, ?| and ?& of PostgreSQL
+			if i+1 < len(query) && query[i+1] == '?' {
+				out.WriteByte('?')
+				i++
+				continue
+			}
+			n++
+			out.WriteString(dialect.Placeholder(n))
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
+
+// countBinds count `?` binds of a neutral query the same way Rebind finds them
+func countBinds(query string) int {
+	n := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(query, i) - 1
+		case '?':
+			if i+1 < len(query) && query[i+1] == '?' {
+				i++
+				continue
+			}
+			n++
+		}
+	}
+	return n
+}
+
+// skipQuoted returns the index after the closing qoute of a qouted section starts at `start`
+func skipQuoted(query string, start int) int {
+	qoute := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if qoute != '`' {
+				i++
+			}
+		case qoute:
+			// doubled qoute is an escaped qoute
+			if i+1 < len(query) && query[i+1] == qoute {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+/**
+	MySQL Dialect
+**/
+
+// MySQLDialect MySQL and MariaDB dialect
+type MySQLDialect struct{}
+
+func (MySQLDialect) Name() string {
+	return "mysql"
+}
+
+func (MySQLDialect) DSN(conn *DBConfig) (string, error) {
+	return conn.String(), nil
+}
+
+func (MySQLDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (MySQLDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (MySQLDialect) LimitOffset(hasLimit, hasOffset bool) string {
+	switch {
+	case hasLimit && hasOffset:
+		return "LIMIT ? OFFSET ?"
+	case hasLimit:
+		return "LIMIT ?"
+	case hasOffset:
+		// mysql dosen't support OFFSET without LIMIT
+		return "LIMIT 18446744073709551615 OFFSET ?"
+	}
+	return ""
+}
+
+func (MySQLDialect) InsertVerb(ignore bool) string {
+	if ignore {
+		return "INSERT IGNORE INTO"
+	}
+	return "INSERT INTO"
+}
+
+func (MySQLDialect) InsertSuffix(ignore bool) string {
+	return ""
+}
+
+func (MySQLDialect) InsertReturning(column string) string {
+	return ""
+}
+
+/**
+	PostgreSQL Dialect
+**/
+
+// PostgresDialect PostgreSQL dialect, works with both lib/pq (postgres) and pgx drivers
+type PostgresDialect struct{}
+
+func (PostgresDialect) Name() string {
+	return "postgres"
+}
+
+func (PostgresDialect) DSN(conn *DBConfig) (string, error) {
+	host := conn.Host
+	if conn.Port > 0 {
+		host = net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port))
+	}
+
+	u := &url.URL{
+		Scheme: "postgres",
+		Host:   host,
+		Path:   "/" + conn.Schema,
+	}
+	if conn.User != "" {
+		u.User = url.UserPassword(conn.User, conn.Password)
+	}
+
+	q := url.Values{}
+	for k, v := range conn.Flags {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func (PostgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (PostgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (PostgresDialect) LimitOffset(hasLimit, hasOffset bool) string {
+	switch {
+	case hasLimit && hasOffset:
+		return "LIMIT ? OFFSET ?"
+	case hasLimit:
+		return "LIMIT ?"
+	case hasOffset:
+		return "OFFSET ?"
+	}
+	return ""
+}
+
+func (PostgresDialect) InsertVerb(ignore bool) string {
+	return "INSERT INTO"
+}
+
+func (PostgresDialect) InsertSuffix(ignore bool) string {
+	if ignore {
+		return " ON CONFLICT DO NOTHING"
+	}
+	return ""
+}
+
+func (PostgresDialect) InsertReturning(column string) string {
+	// pq and pgx don't support LastInsertId
+	return " RETURNING " + qouteColumn(column)
+}
+
+/**
+	SQLite Dialect
+**/
+
+// SQLiteDialect SQLite dialect, works with both modernc (sqlite) and mattn (sqlite3) drivers
+// DBConfig.Path is the database file path or `:memory:`
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Name() string {
+	return "sqlite"
+}
+
+func (SQLiteDialect) DSN(conn *DBConfig) (string, error) {
+	if conn.Path == "" {
+		return "", ErrNoDBPath
+	}
+	if len(conn.Flags) == 0 {
+		return conn.Path, nil
+	}
+
+	q := url.Values{}
+	for k, v := range conn.Flags {
+		q.Set(k, v)
+	}
+
+	dsn := conn.Path
+	if dsn == ":memory:" {
+		// query parameters are only accepted with file uri
+		dsn = "file::memory:"
+	}
+	return dsn + "?" + q.Encode(), nil
+}
+
+func (SQLiteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (SQLiteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (SQLiteDialect) LimitOffset(hasLimit, hasOffset bool) string {
+	switch {
+	case hasLimit && hasOffset:
+		return "LIMIT ? OFFSET ?"
+	case hasLimit:
+		return "LIMIT ?"
+	case hasOffset:
+		// sqlite dosen't support OFFSET without LIMIT
+		return "LIMIT -1 OFFSET ?"
+	}
+	return ""
+}
+
+func (SQLiteDialect) InsertVerb(ignore bool) string {
+	if ignore {
+		return "INSERT OR IGNORE INTO"
+	}
+	return "INSERT INTO"
+}
+
+func (SQLiteDialect) InsertSuffix(ignore bool) string {
+	return ""
+}
+
+func (SQLiteDialect) InsertReturning(column string) string {
+	return ""
+}
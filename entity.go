@@ -0,0 +1,355 @@
+package goje
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// entity struct tag options
+const (
+	TagPrimaryKey    = "pk"
+	TagAutoIncrement = "autoincrement"
+)
+
+// Insert insert the entity into its table
+// zero value autoincrement column is skipped and filled back by the generated id after insert
+func Insert(e Entity) error {
+	ctx, info, v, err := entityOf(e, true)
+	if err != nil {
+		return err
+	}
+
+	if err := runBeforeInsert(ctx, e); err != nil {
+		return err
+	}
+
+	if _, err := insertEntity(ctx, e.GetTableName(), info, v, false); err != nil {
+		return err
+	}
+
+	return runAfterInsert(ctx, e)
+}
+
+// GetOrCreate fetch the entity by match conditions (primary key if match is empty) or insert it if it's missing
+// It relies on a unique key of the match columns: the insert is an INSERT IGNORE (ON CONFLICT DO NOTHING),
+// when a concurrent insert wins the row is fetched again, so the entity holds the stored row either way
+// BeforeInsert hook runs once even if the insert is tried again
+// In a transaction the row is fetched again by a locking read (SELECT ... FOR UPDATE) that sees the committed row
+// under MySQL REPEATABLE READ, PostgreSQL REPEATABLE READ/SERIALIZABLE transactions can't see it and need READ COMMITTED
+func GetOrCreate(ctx *Context, e Entity, match ...QueryInterface) (created bool, err error) {
+	info, v, err := entityValue(e, true)
+	if err != nil {
+		return false, err
+	}
+	if ctx == nil || ctx.DB == nil {
+		return false, ErrHandlerIsNil
+	}
+
+	if len(match) == 0 {
+		if match, err = primaryKeyConditions(info, v); err != nil {
+			return false, err
+		}
+	}
+
+	hooked := false
+	for attempt := 0; attempt < getOrCreateAttempts; attempt++ {
+		// the snapshot of the transaction misses the row inserted concurrently, lock it for reading
+		err = loadEntity(ctx, e.GetTableName(), info, v, match, attempt > 0 && ctx.Tx)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+
+		if !hooked {
+			if err := runBeforeInsert(ctx, e); err != nil {
+				return false, err
+			}
+			hooked = true
+		}
+
+		inserted, err := insertEntity(ctx, e.GetTableName(), info, v, true)
+		if err != nil {
+			return false, err
+		}
+		if inserted {
+			return true, runAfterInsert(ctx, e)
+		}
+		// a concurrent insert won the unique key, fetch it
+	}
+
+	return false, ErrGetOrCreateConflict
+}
+
+// getOrCreateAttempts select/insert rounds of GetOrCreate, a row that conflicts but can't be selected
+// (e.g. deleted meanwhile or match columns aren't the unique key) is retried
+const getOrCreateAttempts = 3
+
+// insertEntity insert struct value v and fill the generated column
+// with ignore, duplicate rows are skipped and inserted is false
+func insertEntity(ctx *Context, Tablename string, info *structInfo, v reflect.Value, ignore bool) (inserted bool, err error) {
+	dialect := ctx.GetDialect()
+
+	var columns []string
+	var args []any
+	var generated *structField
+	for i, field := range info.fields {
+		value, ok := fieldValue(v, field.index)
+		if !ok {
+			continue
+		}
+		if field.hasOption(TagAutoIncrement) && value.IsZero() {
+			generated = &info.fields[i]
+			continue
+		}
+		columns = append(columns, field.column)
+		args = append(args, value.Interface())
+	}
+
+	if len(columns) == 0 {
+		return false, ErrNoRowsColsForInsert
+	}
+
+	values := strings.Repeat(",?", len(columns))
+	query := dialect.InsertVerb(ignore) + " " + qouteColumn(Tablename) +
+		"(" + strings.Join(columnsFilter(columns), ",") + ") VALUES (" + values[1:] + ")" +
+		dialect.InsertSuffix(ignore)
+
+	// drivers without LastInsertId return the generated column
+	if generated != nil {
+		if returning := dialect.InsertReturning(generated.column); returning != "" {
+			target := fieldByIndex(v, generated.index).Addr().Interface()
+			err := ctx.queryRow("Insert", Tablename, Rebind(dialect, query+returning), args).Scan(target)
+			if ignore && errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return err == nil, err
+		}
+	}
+
+	res, err := ctx.exec("Insert", Tablename, Rebind(dialect, query), args)
+	if err != nil {
+		return false, err
+	}
+
+	if ignore {
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		if affected == 0 {
+			return false, nil
+		}
+	}
+
+	if generated == nil {
+		return true, nil
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	return true, setInt(fieldByIndex(v, generated.index), id)
+}
+
+// Update update columns of the entity by its primary key, all non primary key columns if cols is empty
+func Update(e Entity, cols ...string) error {
+	ctx, info, v, err := entityOf(e, false)
+	if err != nil {
+		return err
+	}
+
+	conditions, err := primaryKeyConditions(info, v)
+	if err != nil {
+		return err
+	}
+
+	if err := runBeforeUpdate(ctx, e); err != nil {
+		return err
+	}
+
+	if len(cols) == 0 {
+		for _, field := range info.fields {
+			if !field.hasOption(TagPrimaryKey) {
+				cols = append(cols, field.column)
+			}
+		}
+	}
+
+	values := make(map[string]any, len(cols))
+	for _, col := range cols {
+		field, ok := info.field(col)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownColumn, col)
+		}
+		value, ok := fieldValue(v, field.index)
+		if !ok {
+			values[field.column] = nil
+			continue
+		}
+		values[field.column] = value.Interface()
+	}
+
+	if _, err := ctx.RawUpdate(e.GetTableName(), values, conditions...); err != nil {
+		return err
+	}
+
+	return runAfterUpdate(ctx, e)
+}
+
+// Delete delete the entity by its primary key
+func Delete(e Entity) error {
+	ctx, info, v, err := entityOf(e, false)
+	if err != nil {
+		return err
+	}
+
+	conditions, err := primaryKeyConditions(info, v)
+	if err != nil {
+		return err
+	}
+
+	if err := runBeforeDelete(ctx, e); err != nil {
+		return err
+	}
+
+	if _, err := ctx.RawDelete(e.GetTableName(), conditions); err != nil {
+		return err
+	}
+
+	return runAfterDelete(ctx, e)
+}
+
+// Reload fetch the entity again by its primary key, returns sql.ErrNoRows if it has been deleted
+func Reload(e Entity) error {
+	ctx, info, v, err := entityOf(e, true)
+	if err != nil {
+		return err
+	}
+
+	conditions, err := primaryKeyConditions(info, v)
+	if err != nil {
+		return err
+	}
+
+	return loadEntity(ctx, e.GetTableName(), info, v, conditions, false)
+}
+
+// loadEntity select the first row matches the conditions into struct value v
+// forUpdate makes it a locking read on dialects that support it
+func loadEntity(ctx *Context, Tablename string, info *structInfo, v reflect.Value, conditions []QueryInterface, forUpdate bool) error {
+	dialect := ctx.GetDialect()
+	query, args, err := DialectSelectQueryBuilder(dialect, Tablename, info.columns(), append(conditions[:len(conditions):len(conditions)], Limit(1)))
+	if err != nil {
+		return err
+	}
+	if forUpdate {
+		if _, ok := dialect.(SQLiteDialect); !ok {
+			query += " FOR UPDATE"
+		}
+	}
+
+	rows, err := ctx.query("Select", Tablename, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	scanner, err := newRowScanner(info, rows)
+	if err != nil {
+		return err
+	}
+	if err := scanner.Scan(rows, v); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+// entityOf returns context, db mapping and struct value of the entity
+func entityOf(e Entity, mustPointer bool) (*Context, *structInfo, reflect.Value, error) {
+	if e == nil {
+		return nil, nil, reflect.Value{}, ErrNotAStruct
+	}
+
+	ctx := e.GetCtx()
+	if ctx == nil || ctx.DB == nil {
+		return nil, nil, reflect.Value{}, ErrHandlerIsNil
+	}
+
+	info, v, err := entityValue(e, mustPointer)
+	if err != nil {
+		return nil, nil, reflect.Value{}, err
+	}
+
+	return ctx, info, v, nil
+}
+
+// entityValue returns db mapping and struct value of the entity, the entity may not have a context
+func entityValue(e Entity, mustPointer bool) (*structInfo, reflect.Value, error) {
+	if e == nil {
+		return nil, reflect.Value{}, ErrNotAStruct
+	}
+
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, reflect.Value{}, ErrEntityIsntPointer
+		}
+		v = v.Elem()
+	} else if mustPointer {
+		return nil, reflect.Value{}, ErrEntityIsntPointer
+	}
+
+	info, err := getStructInfo(v.Type())
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+
+	return info, v, nil
+}
+
+// primaryKeyConditions make where conditions of the primary key columns
+func primaryKeyConditions(info *structInfo, v reflect.Value) ([]QueryInterface, error) {
+	var conditions []QueryInterface
+	for _, field := range info.fields {
+		if !field.hasOption(TagPrimaryKey) {
+			continue
+		}
+		value, ok := fieldValue(v, field.index)
+		if !ok {
+			return nil, ErrNoPrimaryKey
+		}
+		conditions = append(conditions, Eq(field.column, value.Interface()))
+	}
+
+	if len(conditions) == 0 {
+		return nil, ErrNoPrimaryKey
+	}
+	return conditions, nil
+}
+
+// setInt set an integer value (e.g. LastInsertId) to the field
+func setInt(field reflect.Value, value int64) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(value))
+	default:
+		if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(value)
+		}
+		return errors.New("can't set generated id to the field of type " + field.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type testPost struct {
+	ctx    *Context
+	ID     int64  `db:"id,pk,autoincrement"`
+	UserID int64  `db:"user_id"`
+	Title  string `db:"title"`
+}
+
+func (p *testPost) GetTableName() string {
+	return "posts"
+}
+
+func (p *testPost) GetColumns() []string {
+	return []string{"id", "user_id", "title"}
+}
+
+func (p *testPost) GetCtx() *Context {
+	return p.ctx
+}
+
+func (p *testPost) GetParent() *Entity {
+	return nil
+}
+
+func TestInsert(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return fakeResult{lastInsertId: 7, rowsAffected: 1}, nil
+	}
+
+	post := &testPost{ctx: MakeHandlerDB(context.Background(), db), UserID: 1, Title: "hello"}
+	if err := Insert(post); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "INSERT INTO `posts`(`user_id`,`title`) VALUES (?,?)"
+	if log := fake.Log(); len(log) != 1 || log[0] != want {
+		t.Errorf("Insert() query = %v, want %v", log, want)
+	}
+	if post.ID != 7 {
+		t.Errorf("Insert() ID = %d, want 7", post.ID)
+	}
+}
+
+func TestInsertReturning(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(9)}}, nil
+	}
+
+	ctx := MakeHandlerDB(context.Background(), db)
+	ctx.Dialect = PostgresDialect{}
+
+	post := &testPost{ctx: ctx, UserID: 1, Title: "hello"}
+	if err := Insert(post); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `INSERT INTO "posts"("user_id","title") VALUES ($1,$2) RETURNING "id"`
+	if log := fake.Log(); len(log) != 1 || log[0] != want {
+		t.Errorf("Insert() query = %v, want %v", log, want)
+	}
+	if post.ID != 9 {
+		t.Errorf("Insert() ID = %d, want 9", post.ID)
+	}
+}
+
+func TestUpdateDelete(t *testing.T) {
+	db, fake := newFakeDB(t)
+	post := &testPost{ctx: MakeHandlerDB(context.Background(), db), ID: 3, UserID: 1, Title: "hello"}
+
+	if err := Update(post, "title"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(post, "unknown"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Update() error = %v, want %v", err, ErrUnknownColumn)
+	}
+	if err := Delete(post); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"UPDATE `posts` SET `title` = ?  WHERE (`id` = ?)",
+		"DELETE FROM `posts`  WHERE (`id` = ?)",
+	}
+	log := fake.Log()
+	if len(log) != len(want) {
+		t.Fatalf("queries = %v, want %v", log, want)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Errorf("query[%d] = %v, want %v", i, log[i], want[i])
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if args[0] != int64(3) {
+			return []string{"id"}, nil, nil
+		}
+		return []string{"id", "user_id", "title"}, [][]driver.Value{{int64(3), int64(1), "reloaded"}}, nil
+	}
+	ctx := MakeHandlerDB(context.Background(), db)
+
+	post := &testPost{ctx: ctx, ID: 3}
+	if err := Reload(post); err != nil {
+		t.Fatal(err)
+	}
+	if post.Title != "reloaded" || post.UserID != 1 {
+		t.Errorf("Reload() = %+v", post)
+	}
+
+	missing := &testPost{ctx: ctx, ID: 4}
+	if err := Reload(missing); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Reload() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestGetOrCreate(t *testing.T) {
+	db, fake := newFakeDB(t)
+	ctx := MakeHandlerDB(context.Background(), db)
+
+	// not found, inserted
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return fakeResult{lastInsertId: 5, rowsAffected: 1}, nil
+	}
+	// a new entity without context
+	post := &testPost{UserID: 1, Title: "hello"}
+	created, err := GetOrCreate(ctx, post, Eq("title", "hello"))
+	if err != nil || !created || post.ID != 5 {
+		t.Fatalf("GetOrCreate() = %v, %v, post = %+v", created, err, post)
+	}
+
+	want := []string{
+		"SELECT `id`,`user_id`,`title`  FROM `posts`  WHERE (`title` = ?) LIMIT ?",
+		"INSERT IGNORE INTO `posts`(`user_id`,`title`) VALUES (?,?)",
+	}
+	if log := fake.Log(); len(log) != 2 || log[0] != want[0] || log[1] != want[1] {
+		t.Errorf("GetOrCreate() queries = %v, want %v", log, want)
+	}
+
+	// a concurrent insert wins the unique key
+	selects := 0
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		selects++
+		if selects == 1 {
+			return []string{"id"}, nil, nil
+		}
+		return []string{"id", "user_id", "title"}, [][]driver.Value{{int64(8), int64(2), "hello"}}, nil
+	}
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return fakeResult{rowsAffected: 0}, nil
+	}
+	post = &testPost{ctx: ctx, UserID: 1, Title: "hello"}
+	created, err = GetOrCreate(ctx, post, Eq("title", "hello"))
+	if err != nil || created || post.ID != 8 || post.UserID != 2 {
+		t.Fatalf("GetOrCreate() = %v, %v, post = %+v", created, err, post)
+	}
+}
+
+func TestGetOrCreateTx(t *testing.T) {
+	db, fake := newFakeDB(t)
+	tx, err := MakeTxHandlerDB(context.Background(), db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the row of the concurrent insert is seen by the third select only
+	var selects []string
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		selects = append(selects, query)
+		if len(selects) < 3 {
+			return []string{"id"}, nil, nil
+		}
+		return []string{"id", "user_id", "title"}, [][]driver.Value{{int64(8), int64(2), "hello"}}, nil
+	}
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return fakeResult{rowsAffected: 0}, nil
+	}
+
+	post := &testHookedPost{testPost: testPost{ctx: tx, UserID: 1, Title: "hello"}}
+	created, err := GetOrCreate(tx, post, Eq("title", "hello"))
+	if err != nil || created || post.ID != 8 {
+		t.Fatalf("GetOrCreate() = %v, %v, post = %+v", created, err, post.testPost)
+	}
+
+	if strings.HasSuffix(selects[0], "FOR UPDATE") || !strings.HasSuffix(selects[1], "LIMIT ? FOR UPDATE") || !strings.HasSuffix(selects[2], "FOR UPDATE") {
+		t.Errorf("GetOrCreate() selects = %v, want locking reads after the conflict", selects)
+	}
+	if !slices.Equal(post.calls, []string{"BeforeInsert"}) {
+		t.Errorf("GetOrCreate() hooks = %v, want BeforeInsert once", post.calls)
+	}
+}
+
+func TestBulkInsertMixedAutoIncrement(t *testing.T) {
+	db, fake := newFakeDB(t)
+	handler := MakeHandlerDB(context.Background(), db)
+
+	_, errs := BulkInsert(handler, false, []Entity{
+		&testPost{Title: "generated"},
+		&testPost{ID: 7, Title: "explicit"},
+		&testPost{Title: "generated too"},
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := fake.Log()
+	if len(log) != 2 {
+		t.Fatalf("queries = %v, want 2 inserts", log)
+	}
+	if strings.Contains(log[0], "`id`") || strings.Count(log[0], "(?,?)") != 2 {
+		t.Errorf("insert of generated ids = %s", log[0])
+	}
+	if !strings.Contains(log[1], "`id`") || strings.Count(log[1], "(?,?,?)") != 1 {
+		t.Errorf("insert of explicit ids = %s", log[1])
+	}
+	if args := fake.LastArgs(); !slices.Contains(args, driver.Value(int64(7))) {
+		t.Errorf("args of explicit ids = %v, want id 7", args)
+	}
+}
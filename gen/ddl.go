@@ -0,0 +1,475 @@
+package gen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/genigo/goje"
+)
+
+// ErrNoTables there isn't any CREATE TABLE statement in the DDL
+var ErrNoTables = errors.New("there isn't any table in the schema")
+
+// ParseDDL reads tables of a SQL DDL dump (mysqldump, pg_dump --schema-only, sqlite .schema)
+// CREATE TABLE and ALTER TABLE ... ADD/MODIFY statements are used, others are ignored
+func ParseDDL(ddl string) (*Schema, error) {
+	schema := &Schema{}
+
+	for _, statement := range splitStatements(tokenize(ddl)) {
+		if len(statement) < 3 {
+			continue
+		}
+		switch {
+		case statement[0].is("CREATE"):
+			parseCreateTable(schema, statement)
+		case statement[0].is("ALTER") && statement[1].is("TABLE"):
+			parseAlterTable(schema, statement)
+		}
+	}
+
+	if len(schema.Tables) == 0 {
+		return nil, ErrNoTables
+	}
+
+	for _, table := range schema.Tables {
+		for _, pk := range table.PrimaryKey {
+			if c := table.Column(pk); c != nil {
+				c.Nullable = false
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is compare a word token case insensitive
+func (t token) is(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+func (t token) isPunct(p string) bool {
+	return t.kind == tokenPunct && t.text == p
+}
+
+// tokenize splits the DDL into tokens, comments are dropped
+func tokenize(ddl string) []token {
+	var tokens []token
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '-' && i+1 < len(ddl) && ddl[i+1] == '-', c == '#':
+			for i < len(ddl) && ddl[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(ddl) && ddl[i+1] == '*':
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 3
+		case c == '`' || c == '"' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var name strings.Builder
+			for i++; i < len(ddl); i++ {
+				if ddl[i] == closing {
+					if i+1 < len(ddl) && ddl[i+1] == closing && closing != ']' {
+						name.WriteByte(closing)
+						i++
+						continue
+					}
+					break
+				}
+				name.WriteByte(ddl[i])
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: name.String()})
+		case c == '\'':
+			start := i
+			for i++; i < len(ddl); i++ {
+				if ddl[i] == '\\' {
+					i++
+					continue
+				}
+				if ddl[i] == '\'' {
+					if i+1 < len(ddl) && ddl[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			end := min(i+1, len(ddl))
+			tokens = append(tokens, token{kind: tokenString, text: ddl[start:end]})
+		case isWordChar(c):
+			start := i
+			for i < len(ddl) && isWordChar(ddl[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: ddl[start:i]})
+			i--
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c)})
+		}
+	}
+	return tokens
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') ||
+		c >= 0x80
+}
+
+// splitStatements splits tokens by `;`
+func splitStatements(tokens []token) [][]token {
+	var out [][]token
+	var current []token
+	for _, t := range tokens {
+		if t.isPunct(";") {
+			if len(current) > 0 {
+				out = append(out, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		out = append(out, current)
+	}
+	return out
+}
+
+// splitTopLevel splits tokens by commas that aren't inside parentheses
+func splitTopLevel(tokens []token) [][]token {
+	var out [][]token
+	var current []token
+	depth := 0
+	for _, t := range tokens {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+		case t.isPunct(",") && depth == 0:
+			out = append(out, current)
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	if len(current) > 0 {
+		out = append(out, current)
+	}
+	return out
+}
+
+// readName reads a (schema qualified) name at i and returns the last part and the next index
+func readName(tokens []token, i int) (string, int) {
+	if i >= len(tokens) {
+		return "", i
+	}
+	name := tokens[i].text
+	i++
+	for i+1 < len(tokens) && tokens[i].isPunct(".") {
+		name = tokens[i+1].text
+		i += 2
+	}
+	return name, i
+}
+
+// readGroup reads a parenthesized group at i and returns its inner tokens and the next index
+func readGroup(tokens []token, i int) ([]token, int) {
+	if i >= len(tokens) || !tokens[i].isPunct("(") {
+		return nil, i
+	}
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		switch {
+		case tokens[j].isPunct("("):
+			depth++
+		case tokens[j].isPunct(")"):
+			depth--
+			if depth == 0 {
+				return tokens[i+1 : j], j + 1
+			}
+		}
+	}
+	return tokens[i+1:], len(tokens)
+}
+
+// readNameList reads `(a, b)` list of column names
+func readNameList(tokens []token, i int) ([]string, int) {
+	group, next := readGroup(tokens, i)
+	var names []string
+	for _, part := range splitTopLevel(group) {
+		if len(part) > 0 {
+			names = append(names, part[0].text)
+		}
+	}
+	return names, next
+}
+
+// skipWords skips optional words sequence like `IF NOT EXISTS`
+func skipWords(tokens []token, i int, words ...string) int {
+	for j, w := range words {
+		if i+j >= len(tokens) || !tokens[i+j].is(w) {
+			return i
+		}
+	}
+	return i + len(words)
+}
+
+// parseCreateTable CREATE [TEMPORARY|UNLOGGED] TABLE [IF NOT EXISTS] name (definitions) options
+func parseCreateTable(schema *Schema, tokens []token) {
+	i := 1
+	for i < len(tokens) && !tokens[i].is("TABLE") {
+		if !tokens[i].is("TEMPORARY") && !tokens[i].is("TEMP") && !tokens[i].is("UNLOGGED") && !tokens[i].is("OR") && !tokens[i].is("REPLACE") {
+			return
+		}
+		i++
+	}
+	i = skipWords(tokens, i+1, "IF", "NOT", "EXISTS")
+
+	name, i := readName(tokens, i)
+	body, _ := readGroup(tokens, i)
+	if name == "" || body == nil {
+		return
+	}
+
+	table := &Table{Name: name}
+	for _, definition := range splitTopLevel(body) {
+		parseDefinition(table, definition)
+	}
+
+	if existing := schema.Table(name); existing != nil {
+		*existing = *table
+		return
+	}
+	schema.Tables = append(schema.Tables, table)
+}
+
+// parseAlterTable ALTER TABLE [ONLY] [IF EXISTS] name ADD ..., MODIFY ..., ALTER COLUMN ... SET DEFAULT nextval(...)
+func parseAlterTable(schema *Schema, tokens []token) {
+	i := skipWords(tokens, 2, "IF", "EXISTS")
+	i = skipWords(tokens, i, "ONLY")
+	name, i := readName(tokens, i)
+
+	table := schema.Table(name)
+	if table == nil {
+		return
+	}
+
+	for _, action := range splitTopLevel(tokens[i:]) {
+		if len(action) < 2 {
+			continue
+		}
+		switch {
+		case action[0].is("ADD"):
+			j := skipWords(action, 1, "COLUMN")
+			parseDefinition(table, action[j:])
+		case action[0].is("MODIFY"):
+			j := skipWords(action, 1, "COLUMN")
+			column := parseColumn(table, action[j:])
+			if column == nil {
+				continue
+			}
+			for k, c := range table.Columns {
+				if c.Name == column.Name {
+					table.Columns[k] = column
+				}
+			}
+		case action[0].is("ALTER"):
+			j := skipWords(action, 1, "COLUMN")
+			columnName, j := readName(action, j)
+			if c := table.Column(columnName); c != nil && hasNextval(action[j:]) {
+				c.AutoIncrement = true
+			}
+		}
+	}
+}
+
+// parseDefinition parse a column or constraint definition of a table
+func parseDefinition(table *Table, tokens []token) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	i := 0
+	constraintName := ""
+	if tokens[0].is("CONSTRAINT") {
+		if len(tokens) < 3 {
+			return
+		}
+		constraintName = tokens[1].text
+		i = 2
+	}
+
+	switch {
+	case tokens[i].is("PRIMARY"):
+		columns, _ := readNameList(tokens, skipWords(tokens, i+1, "KEY"))
+		table.PrimaryKey = columns
+	case tokens[i].is("FOREIGN"):
+		j := skipWords(tokens, i+1, "KEY")
+		if j < len(tokens) && !tokens[j].isPunct("(") {
+			// mysql index name
+			j++
+		}
+		columns, j := readNameList(tokens, j)
+		if fk, ok := readReferences(tokens, j, columns); ok {
+			fk.Name = constraintName
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	case tokens[i].is("UNIQUE"), tokens[i].is("KEY"), tokens[i].is("INDEX"),
+		tokens[i].is("FULLTEXT"), tokens[i].is("SPATIAL"), tokens[i].is("CHECK"),
+		tokens[i].is("EXCLUDE"):
+	default:
+		if constraintName != "" {
+			return
+		}
+		if column := parseColumn(table, tokens); column != nil {
+			table.Columns = append(table.Columns, column)
+		}
+	}
+}
+
+// readReferences REFERENCES table (columns)
+func readReferences(tokens []token, i int, columns []string) (goje.ForeignKey, bool) {
+	if i >= len(tokens) || !tokens[i].is("REFERENCES") {
+		return goje.ForeignKey{}, false
+	}
+	refTable, i := readName(tokens, i+1)
+	refColumns, _ := readNameList(tokens, i)
+	return goje.ForeignKey{
+		Columns:    columns,
+		RefTable:   refTable,
+		RefColumns: refColumns,
+	}, refTable != ""
+}
+
+// columnStopWords end the type part of a column definition
+var columnStopWords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "AUTO_INCREMENT": true, "AUTOINCREMENT": true,
+	"PRIMARY": true, "UNIQUE": true, "COMMENT": true, "REFERENCES": true, "GENERATED": true,
+	"COLLATE": true, "CHARSET": true, "CHECK": true, "ON": true, "CONSTRAINT": true, "AS": true,
+	"INVISIBLE": true, "VISIBLE": true, "STORAGE": true, "COLUMN_FORMAT": true, "SRID": true,
+}
+
+// parseColumn name type [attributes]
+func parseColumn(table *Table, tokens []token) *Column {
+	if len(tokens) < 2 || (tokens[0].kind != tokenWord && tokens[0].kind != tokenIdent) {
+		return nil
+	}
+
+	column := &Column{Name: tokens[0].text, Nullable: true}
+
+	// type
+	var typ strings.Builder
+	i := 1
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == tokenWord && columnStopWords[strings.ToUpper(t.text)] {
+			break
+		}
+		if t.is("CHARACTER") && i+1 < len(tokens) && tokens[i+1].is("SET") {
+			break
+		}
+		if t.isPunct("(") {
+			group, next := readGroup(tokens, i)
+			typ.WriteString("(" + joinTokens(group) + ")")
+			i = next - 1
+			continue
+		}
+		if typ.Len() > 0 {
+			typ.WriteByte(' ')
+		}
+		typ.WriteString(t.text)
+	}
+	column.Type = typ.String()
+
+	switch strings.ToLower(column.Type) {
+	case "serial", "bigserial", "smallserial", "serial4", "serial8", "serial2":
+		column.AutoIncrement = true
+		column.Nullable = false
+	}
+
+	// attributes
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.is("NOT") && i+1 < len(tokens) && tokens[i+1].is("NULL"):
+			column.Nullable = false
+			i++
+		case t.is("NULL"):
+			column.Nullable = true
+		case t.is("AUTO_INCREMENT"), t.is("AUTOINCREMENT"), t.is("IDENTITY"):
+			column.AutoIncrement = true
+		case t.is("DEFAULT") && hasNextval(tokens[i:]):
+			column.AutoIncrement = true
+		case t.is("PRIMARY"):
+			column.Nullable = false
+			if !contains(table.PrimaryKey, column.Name) {
+				table.PrimaryKey = append(table.PrimaryKey, column.Name)
+			}
+		case t.is("REFERENCES"):
+			if fk, ok := readReferences(tokens, i, []string{column.Name}); ok {
+				table.ForeignKeys = append(table.ForeignKeys, fk)
+			}
+		case t.isPunct("("):
+			_, next := readGroup(tokens, i)
+			i = next - 1
+		}
+	}
+
+	return column
+}
+
+// hasNextval detect a postgres sequence default
+func hasNextval(tokens []token) bool {
+	for _, t := range tokens {
+		if t.is("nextval") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinTokens renders tokens of a type argument list: (10,2) ('a','b')
+func joinTokens(tokens []token) string {
+	var out strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.kind == tokenWord && tokens[i-1].kind == tokenWord {
+			out.WriteByte(' ')
+		}
+		out.WriteString(t.text)
+	}
+	return out.String()
+}
+
+func contains(list []string, item string) bool {
+	for _, l := range list {
+		if l == item {
+			return true
+		}
+	}
+	return false
+}
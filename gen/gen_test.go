@@ -0,0 +1,214 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDDL = `
+-- MySQL dump
+/*!40101 SET NAMES utf8mb4 */;
+DROP TABLE IF EXISTS ` + "`users`" + `;
+CREATE TABLE ` + "`users`" + ` (
+  ` + "`id`" + ` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  ` + "`name`" + ` varchar(255) NOT NULL DEFAULT '',
+  ` + "`email`" + ` varchar(255) DEFAULT NULL COMMENT 'it''s, optional',
+  ` + "`score`" + ` decimal(10,2) DEFAULT NULL,
+  ` + "`created_at`" + ` datetime NOT NULL,
+  PRIMARY KEY (` + "`id`" + `),
+  UNIQUE KEY ` + "`email`" + ` (` + "`email`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE public.orders (
+    id bigserial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id),
+    total double precision,
+    paid boolean,
+    note character varying(100)
+);
+
+CREATE TABLE order_items (
+    order_id bigint NOT NULL,
+    sku text NOT NULL,
+    CONSTRAINT order_items_order_fk FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+ALTER TABLE ONLY order_items ADD CONSTRAINT order_items_pkey PRIMARY KEY (order_id, sku);
+`
+
+func TestParseDDL(t *testing.T) {
+	schema, err := ParseDDL(testDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables) != 3 {
+		t.Fatalf("ParseDDL() tables = %d, want 3", len(schema.Tables))
+	}
+
+	users := schema.Table("users")
+	if len(users.Columns) != 5 {
+		t.Fatalf("users columns = %d, want 5", len(users.Columns))
+	}
+	if id := users.Column("id"); id.Type != "int(10) unsigned" || !id.AutoIncrement || id.Nullable {
+		t.Errorf("users.id = %+v", id)
+	}
+	if email := users.Column("email"); !email.Nullable || email.Type != "varchar(255)" {
+		t.Errorf("users.email = %+v", email)
+	}
+	if score := users.Column("score"); score.Type != "decimal(10,2)" {
+		t.Errorf("users.score = %+v", score)
+	}
+	if len(users.PrimaryKey) != 1 || users.PrimaryKey[0] != "id" {
+		t.Errorf("users primary key = %v", users.PrimaryKey)
+	}
+
+	orders := schema.Table("orders")
+	if id := orders.Column("id"); !id.AutoIncrement || id.Nullable || !orders.IsPrimaryKey("id") {
+		t.Errorf("orders.id = %+v, pk = %v", id, orders.PrimaryKey)
+	}
+	if total := orders.Column("total"); total.Type != "double precision" {
+		t.Errorf("orders.total = %+v", total)
+	}
+	if note := orders.Column("note"); note.Type != "character varying(100)" {
+		t.Errorf("orders.note = %+v", note)
+	}
+	if len(orders.ForeignKeys) != 1 || orders.ForeignKeys[0].RefTable != "users" || orders.ForeignKeys[0].Columns[0] != "user_id" {
+		t.Errorf("orders foreign keys = %+v", orders.ForeignKeys)
+	}
+
+	items := schema.Table("order_items")
+	if len(items.PrimaryKey) != 2 {
+		t.Errorf("order_items primary key = %v", items.PrimaryKey)
+	}
+	if len(items.ForeignKeys) != 1 || items.ForeignKeys[0].Name != "order_items_order_fk" {
+		t.Errorf("order_items foreign keys = %+v", items.ForeignKeys)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":     "UserID",
+		"order_items": "OrderItems",
+		"api_url":     "APIURL",
+		"createdAt":   "CreatedAt",
+		"2fa":         "X2fa",
+	}
+	for in, want := range tests {
+		if got := GoName(in); got != want {
+			t.Errorf("GoName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		column Column
+		null   string
+		want   string
+	}{
+		{Column{Type: "int(10) unsigned"}, NullSQL, "uint32"},
+		{Column{Type: "tinyint(1)"}, NullSQL, "bool"},
+		{Column{Type: "bigint", Nullable: true}, NullSQL, "sql.NullInt64"},
+		{Column{Type: "bigint", Nullable: true}, NullPointer, "*int64"},
+		{Column{Type: "int unsigned", Nullable: true}, NullSQL, "sql.Null[uint32]"},
+		{Column{Type: "timestamp with time zone", Nullable: true}, NullSQL, "sql.NullTime"},
+		{Column{Type: "datetime"}, NullSQL, "time.Time"},
+		{Column{Type: "longblob", Nullable: true}, NullPointer, "[]byte"},
+		{Column{Type: "decimal(10,2)"}, NullSQL, "string"},
+	}
+	for _, tt := range tests {
+		if got, _ := GoType(&tt.column, tt.null); got != tt.want {
+			t.Errorf("GoType(%q, nullable=%v, %s) = %q, want %q", tt.column.Type, tt.column.Nullable, tt.null, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	schema, err := ParseDDL(testDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Generate(schema, Options{Package: "models", Tables: []string{"users", "orders"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Generate() files = %d, want 2", len(files))
+	}
+
+	src := string(files["users.goje.go"])
+	for _, want := range []string{
+		"// Code generated by goje gen. DO NOT EDIT.",
+		"package models",
+		`const UsersTable = "users"`,
+		`UsersColumnCreatedAt = "created_at"`,
+		"var UsersPrimaryKey = []string{UsersColumnID}",
+		"ID        uint32         `db:\"id,pk,autoincrement\"`",
+		"Email     sql.NullString `db:\"email\"`",
+		"CreatedAt time.Time      `db:\"created_at\"`",
+		"func (e *Users) GetTableName() string",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("users.goje.go doesn't contain %q\n%s", want, src)
+		}
+	}
+
+	if src := string(files["orders.goje.go"]); !strings.Contains(src, `{Name: "", Columns: []string{OrdersColumnUserID}, RefTable: "users", RefColumns: []string{"id"}}`) {
+		t.Errorf("orders.goje.go foreign keys\n%s", src)
+	}
+	if strings.Contains(src, "GetDBName") {
+		t.Errorf("users.goje.go has GetDBName without DBName option")
+	}
+
+	files, err = Generate(schema, Options{Tables: []string{"users"}, DBName: "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := string(files["users.goje.go"]); !strings.Contains(src, "func (e *Users) GetDBName() string {\n\treturn \"billing\"") {
+		t.Errorf("users.goje.go doesn't declare its database\n%s", src)
+	}
+}
+
+func TestGenerateRelations(t *testing.T) {
+	schema, err := ParseDDL(testDDL + `
+CREATE TABLE tags (id int PRIMARY KEY, name text NOT NULL);
+CREATE TABLE order_tags (
+    order_id bigint NOT NULL REFERENCES orders (id),
+    tag_id int NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (order_id, tag_id)
+);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Generate(schema, Options{Tables: []string{"users", "orders", "tags", "order_items"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"users.goje.go": {
+			"Orders []*Orders `db:\"-\"`",
+			`{Name: "Orders", Kind: goje.HasMany, Table: "orders", LocalKey: "id", ForeignKey: "user_id"}`,
+			"func (e *Users) SetParent(parent *goje.Entity)",
+		},
+		"orders.goje.go": {
+			"User       *Users        `db:\"-\"`",
+			`{Name: "User", Kind: goje.BelongsTo, Table: "users", LocalKey: "user_id", ForeignKey: "id"}`,
+			`{Name: "OrderItems", Kind: goje.HasMany, Table: "order_items", LocalKey: "id", ForeignKey: "order_id"}`,
+			`{Name: "Tags", Kind: goje.ManyToMany, Table: "tags", LocalKey: "id", ForeignKey: "id", JoinTable: "order_tags", JoinLocalKey: "order_id", JoinForeignKey: "tag_id"}`,
+		},
+		"tags.goje.go": {
+			`{Name: "Orders", Kind: goje.ManyToMany, Table: "orders", LocalKey: "id", ForeignKey: "id", JoinTable: "order_tags", JoinLocalKey: "tag_id", JoinForeignKey: "order_id"}`,
+		},
+	}
+	for file, wants := range tests {
+		src := string(files[file])
+		for _, want := range wants {
+			if !strings.Contains(src, want) {
+				t.Errorf("%s doesn't contain %q\n%s", file, want, src)
+			}
+		}
+	}
+}
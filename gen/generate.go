@@ -0,0 +1,499 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/genigo/goje"
+)
+
+// Nullable column styles
+const (
+	NullSQL     = "sql"
+	NullPointer = "pointer"
+)
+
+// Options of the generator
+type Options struct {
+	// Package name of the generated files
+	Package string
+	// Tables limits generation to these tables, all tables if empty
+	Tables []string
+	// Null style of nullable columns: NullSQL (sql.Null*) or NullPointer (*T)
+	Null string
+	// DBName of the registered database (goje.RegisterDB) that entities live in, entities implement goje.DBNamer if it's set
+	DBName string
+}
+
+// Generate makes Go source of the entities, returns [file name]source
+func Generate(schema *Schema, opts Options) (map[string][]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "models"
+	}
+	if opts.Null == "" {
+		opts.Null = NullSQL
+	}
+	if opts.Null != NullSQL && opts.Null != NullPointer {
+		return nil, fmt.Errorf("unknown null style %q, use %q or %q", opts.Null, NullSQL, NullPointer)
+	}
+
+	out := map[string][]byte{}
+	for _, table := range schema.Tables {
+		if !opts.generates(table.Name) {
+			continue
+		}
+
+		src, err := generateTable(schema, table, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", table.Name, err)
+		}
+		out[table.Name+".goje.go"] = src
+	}
+
+	if len(out) == 0 {
+		return nil, ErrNoTables
+	}
+	return out, nil
+}
+
+// generates check the table is generated by the options
+func (opts Options) generates(table string) bool {
+	return len(opts.Tables) == 0 || contains(opts.Tables, table)
+}
+
+type entityField struct {
+	Name     string
+	Type     string
+	Tag      string
+	Constant string
+	Column   string
+}
+
+type entityData struct {
+	Package     string
+	Table       *Table
+	Struct      string
+	Imports     []string
+	Fields      []entityField
+	PrimaryKey  []string
+	ForeignKeys []string
+	Relations   []entityRelation
+	DBName      string
+}
+
+type entityRelation struct {
+	Field string
+	Type  string
+	// Value is goje.Relation literal
+	Value string
+}
+
+func generateTable(schema *Schema, table *Table, opts Options) ([]byte, error) {
+	data := entityData{
+		Package: opts.Package,
+		Table:   table,
+		Struct:  GoName(table.Name),
+		DBName:  opts.DBName,
+	}
+
+	imports := map[string]bool{"github.com/genigo/goje": true}
+	constants := map[string]string{}
+	for _, column := range table.Columns {
+		typ, pkg := GoType(column, opts.Null)
+		if pkg != "" {
+			imports[pkg] = true
+		}
+
+		tag := column.Name
+		if table.IsPrimaryKey(column.Name) {
+			tag += ",pk"
+		}
+		if column.AutoIncrement {
+			tag += ",autoincrement"
+		}
+
+		field := entityField{
+			Name:     GoName(column.Name),
+			Type:     typ,
+			Tag:      "`db:\"" + tag + "\"`",
+			Constant: data.Struct + "Column" + GoName(column.Name),
+			Column:   column.Name,
+		}
+		constants[column.Name] = field.Constant
+		data.Fields = append(data.Fields, field)
+	}
+
+	for _, pk := range table.PrimaryKey {
+		data.PrimaryKey = append(data.PrimaryKey, constants[pk])
+	}
+
+	for _, fk := range table.ForeignKeys {
+		columns := make([]string, len(fk.Columns))
+		for i, c := range fk.Columns {
+			columns[i] = constants[c]
+			if columns[i] == "" {
+				columns[i] = fmt.Sprintf("%q", c)
+			}
+		}
+		refColumns := make([]string, len(fk.RefColumns))
+		for i, c := range fk.RefColumns {
+			refColumns[i] = fmt.Sprintf("%q", c)
+		}
+		data.ForeignKeys = append(data.ForeignKeys, fmt.Sprintf(
+			"{Name: %q, Columns: []string{%s}, RefTable: %q, RefColumns: []string{%s}}",
+			fk.Name, strings.Join(columns, ", "), fk.RefTable, strings.Join(refColumns, ", "),
+		))
+	}
+
+	data.Relations = relations(schema, table, data.Fields, opts)
+
+	for pkg := range imports {
+		data.Imports = append(data.Imports, pkg)
+	}
+	sort.Strings(data.Imports)
+
+	var buf bytes.Buffer
+	if err := entityTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// relations finds relations of the table by foreign keys, relations to tables that aren't generated are skipped
+//
+//	belongs-to: orders.user_id -> users.id makes Orders.User
+//	has-many: users.id <- orders.user_id makes Users.Orders
+//	many-to-many: a join table with two foreign keys as its primary key, posts <- post_tags -> tags makes Posts.Tags
+func relations(schema *Schema, table *Table, fields []entityField, opts Options) []entityRelation {
+	used := map[string]bool{}
+	for _, f := range fields {
+		used[f.Name] = true
+	}
+	unique := func(name string) string {
+		for used[name] {
+			name += "Rel"
+		}
+		used[name] = true
+		return name
+	}
+
+	var out []entityRelation
+	add := func(field, typ string, relation goje.Relation) {
+		relation.Name = unique(field)
+		value := fmt.Sprintf("{Name: %q, Kind: goje.%s, Table: %q, LocalKey: %q, ForeignKey: %q",
+			relation.Name, kinds[relation.Kind], relation.Table, relation.LocalKey, relation.ForeignKey)
+		if relation.JoinTable != "" {
+			value += fmt.Sprintf(", JoinTable: %q, JoinLocalKey: %q, JoinForeignKey: %q",
+				relation.JoinTable, relation.JoinLocalKey, relation.JoinForeignKey)
+		}
+		out = append(out, entityRelation{Field: relation.Name, Type: typ, Value: value + "}"})
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Columns) != 1 || len(fk.RefColumns) != 1 || !opts.generates(fk.RefTable) || schema.Table(fk.RefTable) == nil {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimSuffix(fk.Columns[0], "_id"), "_ID")
+		add(GoName(name), "*"+GoName(fk.RefTable), goje.Relation{
+			Kind: goje.BelongsTo, Table: fk.RefTable, LocalKey: fk.Columns[0], ForeignKey: fk.RefColumns[0],
+		})
+	}
+
+	for _, other := range schema.Tables {
+		if !opts.generates(other.Name) && !isJoinTable(other) {
+			continue
+		}
+		for i, fk := range other.ForeignKeys {
+			if len(fk.Columns) != 1 || len(fk.RefColumns) != 1 || fk.RefTable != table.Name {
+				continue
+			}
+
+			if !isJoinTable(other) {
+				add(GoName(other.Name), "[]*"+GoName(other.Name), goje.Relation{
+					Kind: goje.HasMany, Table: other.Name, LocalKey: fk.RefColumns[0], ForeignKey: fk.Columns[0],
+				})
+				continue
+			}
+
+			far := other.ForeignKeys[1-i]
+			if !opts.generates(far.RefTable) || schema.Table(far.RefTable) == nil {
+				continue
+			}
+			add(GoName(far.RefTable), "[]*"+GoName(far.RefTable), goje.Relation{
+				Kind: goje.ManyToMany, Table: far.RefTable, LocalKey: fk.RefColumns[0], ForeignKey: far.RefColumns[0],
+				JoinTable: other.Name, JoinLocalKey: fk.Columns[0], JoinForeignKey: far.Columns[0],
+			})
+		}
+	}
+	return out
+}
+
+var kinds = map[goje.RelationKind]string{
+	goje.BelongsTo:  "BelongsTo",
+	goje.HasOne:     "HasOne",
+	goje.HasMany:    "HasMany",
+	goje.ManyToMany: "ManyToMany",
+}
+
+// isJoinTable a table with two single column foreign keys that make its primary key
+func isJoinTable(table *Table) bool {
+	if len(table.ForeignKeys) != 2 || len(table.PrimaryKey) != 2 {
+		return false
+	}
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Columns) != 1 || len(fk.RefColumns) != 1 || !table.IsPrimaryKey(fk.Columns[0]) {
+			return false
+		}
+	}
+	return table.ForeignKeys[0].Columns[0] != table.ForeignKeys[1].Columns[0]
+}
+
+// GoType maps SQL type of the column to a Go type and its import path
+func GoType(column *Column, null string) (string, string) {
+	lower := strings.ToLower(strings.TrimSpace(column.Type))
+	unsigned := strings.Contains(lower, "unsigned")
+	base := lower
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+
+	typ := "string"
+	switch base {
+	case "bool", "boolean":
+		typ = "bool"
+	case "tinyint":
+		switch {
+		case strings.HasPrefix(lower, "tinyint(1)"):
+			typ = "bool"
+		case unsigned:
+			typ = "uint8"
+		default:
+			typ = "int8"
+		}
+	case "smallint", "int2", "smallserial", "serial2", "year":
+		typ = "int16"
+		if unsigned {
+			typ = "uint16"
+		}
+	case "mediumint", "int", "integer", "int4", "serial", "serial4":
+		typ = "int32"
+		if unsigned {
+			typ = "uint32"
+		}
+	case "bigint", "int8", "bigserial", "serial8":
+		typ = "int64"
+		if unsigned {
+			typ = "uint64"
+		}
+	case "float", "real", "float4":
+		typ = "float32"
+	case "double", "float8":
+		typ = "float64"
+	case "date", "datetime", "timestamp", "timestamptz":
+		typ = "time.Time"
+	case "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "bytea":
+		typ = "[]byte"
+	}
+
+	if !column.Nullable || typ == "[]byte" {
+		return typ, importOf(typ)
+	}
+
+	if null == NullPointer {
+		return "*" + typ, importOf(typ)
+	}
+
+	switch typ {
+	case "bool":
+		return "sql.NullBool", "database/sql"
+	case "uint8":
+		return "sql.NullByte", "database/sql"
+	case "int16":
+		return "sql.NullInt16", "database/sql"
+	case "int32":
+		return "sql.NullInt32", "database/sql"
+	case "int64":
+		return "sql.NullInt64", "database/sql"
+	case "float64":
+		return "sql.NullFloat64", "database/sql"
+	case "string":
+		return "sql.NullString", "database/sql"
+	case "time.Time":
+		return "sql.NullTime", "database/sql"
+	}
+	return "sql.Null[" + typ + "]", "database/sql"
+}
+
+func importOf(typ string) string {
+	if strings.Contains(typ, "time.") {
+		return "time"
+	}
+	return ""
+}
+
+// initialisms are upper cased in Go names
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SKU": true, "SQL": true, "SSH": true, "TLS": true,
+	"TTL": true, "UID": true, "UUID": true, "URI": true, "URL": true, "XML": true,
+}
+
+// GoName converts a snake_case SQL name to an exported Go name: user_id -> UserID
+func GoName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var out strings.Builder
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); initialisms[upper] {
+			out.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		out.WriteString(string(runes))
+	}
+
+	if out.Len() == 0 {
+		return "X"
+	}
+	result := out.String()
+	if unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+var entityTemplate = template.Must(template.New("entity").Parse(`// Code generated by goje gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+// {{ .Struct }}Table name of ` + "`{{ .Table.Name }}`" + ` table
+const {{ .Struct }}Table = "{{ .Table.Name }}"
+
+// Columns of ` + "`{{ .Table.Name }}`" + ` table
+const (
+{{- range .Fields }}
+	{{ .Constant }} = "{{ .Column }}"
+{{- end }}
+)
+
+// {{ .Struct }}PrimaryKey primary key columns of ` + "`{{ .Table.Name }}`" + ` table
+var {{ .Struct }}PrimaryKey = []string{ {{- range $i, $c := .PrimaryKey }}{{ if $i }}, {{ end }}{{ $c }}{{ end -}} }
+
+// {{ .Struct }}ForeignKeys foreign keys of ` + "`{{ .Table.Name }}`" + ` table
+var {{ .Struct }}ForeignKeys = []goje.ForeignKey{
+{{- range .ForeignKeys }}
+	{{ . }},
+{{- end }}
+}
+
+// {{ .Struct }} entity of ` + "`{{ .Table.Name }}`" + ` table
+type {{ .Struct }} struct {
+	ctx    *goje.Context
+	parent *goje.Entity
+
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} {{ .Tag }}
+{{- end }}
+{{- if .Relations }}
+
+	// relations, filled by goje.Load
+{{- range .Relations }}
+	{{ .Field }} {{ .Type }} ` + "`db:\"-\"`" + `
+{{- end }}
+{{- end }}
+}
+
+var (
+	_ goje.Entity         = (*{{ .Struct }})(nil)
+	_ goje.RelationEntity = (*{{ .Struct }})(nil)
+{{- if .DBName }}
+	_ goje.DBNamer        = (*{{ .Struct }})(nil)
+{{- end }}
+)
+
+// New{{ .Struct }} makes an entity bound to the context
+func New{{ .Struct }}(ctx *goje.Context) *{{ .Struct }} {
+	return &{{ .Struct }}{ctx: ctx}
+}
+
+// GetTableName returns table name of the entity
+func (e *{{ .Struct }}) GetTableName() string {
+	return {{ .Struct }}Table
+}
+
+// GetColumns returns columns of the entity
+func (e *{{ .Struct }}) GetColumns() []string {
+	return []string{
+{{- range .Fields }}
+		{{ .Constant }},
+{{- end }}
+	}
+}
+
+// GetCtx returns the context of the entity
+func (e *{{ .Struct }}) GetCtx() *goje.Context {
+	return e.ctx
+}
+
+// SetCtx binds the entity to the context
+func (e *{{ .Struct }}) SetCtx(ctx *goje.Context) {
+	e.ctx = ctx
+}
+
+// GetParent returns the entity that loaded this entity
+func (e *{{ .Struct }}) GetParent() *goje.Entity {
+	return e.parent
+}
+
+// SetParent sets the entity that loaded this entity
+func (e *{{ .Struct }}) SetParent(parent *goje.Entity) {
+	e.parent = parent
+}
+
+// GetPrimaryKey returns primary key columns
+func (e *{{ .Struct }}) GetPrimaryKey() []string {
+	return {{ .Struct }}PrimaryKey
+}
+
+// GetForeignKeys returns foreign keys metadata
+func (e *{{ .Struct }}) GetForeignKeys() []goje.ForeignKey {
+	return {{ .Struct }}ForeignKeys
+}
+
+{{- if .DBName }}
+
+// GetDBName returns name of the registered database of the entity
+func (e *{{ .Struct }}) GetDBName() string {
+	return {{ printf "%q" .DBName }}
+}
+{{- end }}
+
+// GetRelations returns relations that goje.Load fills
+func (e *{{ .Struct }}) GetRelations() []goje.Relation {
+	return []goje.Relation{
+{{- range .Relations }}
+		{{ .Value }},
+{{- end }}
+	}
+}
+`))
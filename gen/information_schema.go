@@ -0,0 +1,99 @@
+package gen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/genigo/goje"
+)
+
+// ErrLiveSchemaUnsupported live schema loading isn't implemented for the dialect
+var ErrLiveSchemaUnsupported = errors.New("reading live schema supports mysql only, use a DDL dump instead")
+
+type schemaColumn struct {
+	TableName  string `db:"table_name"`
+	ColumnName string `db:"column_name"`
+	ColumnType string `db:"column_type"`
+	IsNullable string `db:"is_nullable"`
+	ColumnKey  string `db:"column_key"`
+	Extra      string `db:"extra"`
+}
+
+type schemaForeignKey struct {
+	ConstraintName       string `db:"constraint_name"`
+	TableName            string `db:"table_name"`
+	ColumnName           string `db:"column_name"`
+	ReferencedTableName  string `db:"referenced_table_name"`
+	ReferencedColumnName string `db:"referenced_column_name"`
+}
+
+// LoadSchema reads tables of the database schema from information_schema
+func LoadSchema(ctx *goje.Context, database string) (*Schema, error) {
+	if ctx.GetDialect().Name() != "mysql" {
+		return nil, ErrLiveSchemaUnsupported
+	}
+
+	columns, err := goje.Select[schemaColumn](ctx, "information_schema.columns",
+		goje.Eq("table_schema", database),
+		goje.Order("table_name, ordinal_position"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	for _, c := range columns {
+		table := schema.Table(c.TableName)
+		if table == nil {
+			table = &Table{Name: c.TableName}
+			schema.Tables = append(schema.Tables, table)
+		}
+
+		table.Columns = append(table.Columns, &Column{
+			Name:          c.ColumnName,
+			Type:          c.ColumnType,
+			Nullable:      c.IsNullable == "YES",
+			AutoIncrement: strings.Contains(strings.ToLower(c.Extra), "auto_increment"),
+		})
+		if c.ColumnKey == "PRI" {
+			table.PrimaryKey = append(table.PrimaryKey, c.ColumnName)
+		}
+	}
+
+	if len(schema.Tables) == 0 {
+		return nil, ErrNoTables
+	}
+
+	keys, err := goje.Select[schemaForeignKey](ctx, "information_schema.key_column_usage",
+		goje.Eq("table_schema", database),
+		goje.Where("referenced_table_name IS NOT NULL"),
+		goje.Order("table_name, constraint_name, ordinal_position"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		table := schema.Table(k.TableName)
+		if table == nil {
+			continue
+		}
+
+		// multi column foreign keys come in consecutive rows
+		if n := len(table.ForeignKeys); n > 0 && table.ForeignKeys[n-1].Name == k.ConstraintName {
+			fk := &table.ForeignKeys[n-1]
+			fk.Columns = append(fk.Columns, k.ColumnName)
+			fk.RefColumns = append(fk.RefColumns, k.ReferencedColumnName)
+			continue
+		}
+
+		table.ForeignKeys = append(table.ForeignKeys, goje.ForeignKey{
+			Name:       k.ConstraintName,
+			Columns:    []string{k.ColumnName},
+			RefTable:   k.ReferencedTableName,
+			RefColumns: []string{k.ReferencedColumnName},
+		})
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,57 @@
+// Package gen generates goje entities from a database schema
+// the schema is read from a live database (information_schema) or a SQL DDL dump
+package gen
+
+import "github.com/genigo/goje"
+
+// Schema tables of a database
+type Schema struct {
+	Tables []*Table
+}
+
+// Table find a table by name
+func (s *Schema) Table(name string) *Table {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Table columns and keys of a table
+type Table struct {
+	Name        string
+	Columns     []*Column
+	PrimaryKey  []string
+	ForeignKeys []goje.ForeignKey
+}
+
+// Column find a column by name
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// IsPrimaryKey check the column is a part of primary key
+func (t *Table) IsPrimaryKey(column string) bool {
+	for _, pk := range t.PrimaryKey {
+		if pk == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Column definition of a table column
+type Column struct {
+	Name string
+	// Type is the SQL type as declared: int unsigned, varchar(255), decimal(10,2) ...
+	Type          string
+	Nullable      bool
+	AutoIncrement bool
+}
@@ -11,7 +11,7 @@ import (
 // Default Database connection that fill by
 var DefatultDB *sql.DB
 
-// Default slow query log threshold = 5s
+// Default slow query log threshold = 5s, slow queries are logged at warn level by QueryLogger
 var SlowQueryLogTimeout = time.Second * 5
 
 // InitDB Connect default database
@@ -21,35 +21,53 @@ func InitDB(conn *DBConfig) error {
 		return err
 	}
 	DefatultDB = db
+	DefaultDialect = dialectOf(db)
 	return nil
 }
 
 // NewDBConnection Connect to database and return database
 func NewDBConnection(conn *DBConfig) (*sql.DB, error) {
-	if conn.Driver != "mysql" {
-		return nil, ErrUnknownDBDriver
+	dialect, err := GetDialect(conn.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := dialect.DSN(conn)
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := sql.Open(conn.Driver, conn.String())
+	db, err := sql.Open(conn.Driver, dsn)
 
 	if err != nil {
 		return nil, err
 	}
+	h := handleFor(db)
+	h.dialect = dialect
+	h.log = conn.Log
+	h.name = conn.name()
+	h.pooled = true
 
 	db.SetConnMaxIdleTime(conn.MaxIdleTime)
 	db.SetMaxIdleConns(conn.MaxIdleConns)
 	db.SetMaxOpenConns(conn.MaxOpenConns)
 	db.SetConnMaxLifetime(conn.ConnMaxLifetime)
 
+	// each connection of an in-memory sqlite opens a separate empty database,
+	// keep only one connection (whatever MaxOpenConns is) open forever so tables live between statements
+	if _, ok := dialect.(SQLiteDialect); ok && conn.Path == ":memory:" {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+
 	return db, nil
 }
 
 // GetHandler make a handler from default database and a TODO context
 func GetHandler() *Context {
-	return &Context{
-		Ctx: context.TODO(),
-		DB:  DefatultDB,
-	}
+	return MakeHandler(context.TODO())
 }
 
 // H is a shortcut for GetHandler
@@ -59,44 +77,20 @@ func H() *Context {
 
 // MakeHandler make a handler from default database
 func MakeHandler(ctx context.Context) *Context {
-	return &Context{
-		Ctx: ctx,
-		DB:  DefatultDB,
-	}
+	return MakeHandlerDB(ctx, DefatultDB)
 }
 
 // DefaultHandler make a handler from default database
 func MakeTxHandler(ctx context.Context, options *sql.TxOptions) (*Context, error) {
-	tx, err := DefatultDB.BeginTx(ctx, options)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Context{
-		Ctx: ctx,
-		DB:  tx,
-		Tx:  true,
-	}, nil
+	return MakeTxHandlerDB(ctx, DefatultDB, options)
 }
 
 // MakeHandler make a handler from the database connection
 func MakeHandlerDB(ctx context.Context, db *sql.DB) *Context {
-	return &Context{
-		Ctx: ctx,
-		DB:  db,
-	}
+	return newHandler(ctx, db, db)
 }
 
 // DefaultHandler make a handler from the database connection
 func MakeTxHandlerDB(ctx context.Context, db *sql.DB, options *sql.TxOptions) (*Context, error) {
-	tx, err := db.BeginTx(ctx, options)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Context{
-		Ctx: ctx,
-		DB:  tx,
-		Tx:  true,
-	}, nil
+	return MakeHandlerDB(ctx, db).Begin(options)
 }
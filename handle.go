@@ -0,0 +1,72 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+)
+
+// dbHandle settings of a database connection, contexts made from the connection inherit them
+type dbHandle struct {
+	mu      sync.RWMutex
+	dialect Dialect
+	// name of the connection in metrics, pooled connections (opened by NewDBConnection) are in PoolStats
+	name        string
+	pooled      bool
+	middlewares []Middleware
+	log         LogConfig
+	// cluster of a primary, its handlers read from replicas
+	cluster *Cluster
+	// slowQueries counter of sampling
+	slowQueries atomic.Uint64
+	// queries running queries, Managed.Shutdown waits for them
+	queries queryTracker
+}
+
+// dbHandles: [*sql.DB]*dbHandle of connections opened by NewDBConnection or configured by UseDB
+var dbHandles sync.Map
+
+// handleOf returns settings of the connection, nil if it hasn't any
+func handleOf(db *sql.DB) *dbHandle {
+	if db == nil {
+		return nil
+	}
+	if h, ok := dbHandles.Load(db); ok {
+		return h.(*dbHandle)
+	}
+	return nil
+}
+
+// CloseDB closes the connection and drops its settings, so it isn't in PoolStats anymore
+func CloseDB(db *sql.DB) error {
+	dbHandles.Delete(db)
+	return db.Close()
+}
+
+// handleFor returns settings of the connection, makes them if it hasn't any
+func handleFor(db *sql.DB) *dbHandle {
+	h, _ := dbHandles.LoadOrStore(db, &dbHandle{})
+	return h.(*dbHandle)
+}
+
+// newHandler make a handler runs queries by conn (db or a tx of it) with settings of db
+func newHandler(ctx context.Context, db *sql.DB, conn QueryAble) *Context {
+	_, tx := conn.(*sql.Tx)
+	c := &Context{Ctx: ctx, DB: conn, Tx: tx}
+	h := handleOf(db)
+	if h == nil {
+		return c
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	c.Dialect = h.dialect
+	c.dbName = h.name
+	c.cluster = h.cluster
+	c.middlewares = append([]Middleware(nil), h.middlewares...)
+	c.log = h.log
+	c.slowQueries = &h.slowQueries
+	c.queries = &h.queries
+	return c
+}
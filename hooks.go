@@ -0,0 +1,90 @@
+package goje
+
+import "errors"
+
+// Entity lifecycle hooks, entities implement them optionally
+// Insert, Update, Delete and BulkInsert call them with the active context,
+// an error aborts the write and rolls back the transaction of a transactional context
+
+// BeforeInsert called before an entity insert
+type BeforeInsert interface {
+	BeforeInsert(ctx *Context) error
+}
+
+// AfterInsert called after an entity insert
+type AfterInsert interface {
+	AfterInsert(ctx *Context) error
+}
+
+// BeforeUpdate called before an entity update
+type BeforeUpdate interface {
+	BeforeUpdate(ctx *Context) error
+}
+
+// AfterUpdate called after an entity update
+type AfterUpdate interface {
+	AfterUpdate(ctx *Context) error
+}
+
+// BeforeDelete called before an entity delete
+type BeforeDelete interface {
+	BeforeDelete(ctx *Context) error
+}
+
+// AfterDelete called after an entity delete
+type AfterDelete interface {
+	AfterDelete(ctx *Context) error
+}
+
+func runBeforeInsert(ctx *Context, e Entity) error {
+	if h, ok := e.(BeforeInsert); ok {
+		return abortOnHookError(ctx, h.BeforeInsert(ctx))
+	}
+	return nil
+}
+
+func runAfterInsert(ctx *Context, e Entity) error {
+	if h, ok := e.(AfterInsert); ok {
+		return abortOnHookError(ctx, h.AfterInsert(ctx))
+	}
+	return nil
+}
+
+func runBeforeUpdate(ctx *Context, e Entity) error {
+	if h, ok := e.(BeforeUpdate); ok {
+		return abortOnHookError(ctx, h.BeforeUpdate(ctx))
+	}
+	return nil
+}
+
+func runAfterUpdate(ctx *Context, e Entity) error {
+	if h, ok := e.(AfterUpdate); ok {
+		return abortOnHookError(ctx, h.AfterUpdate(ctx))
+	}
+	return nil
+}
+
+func runBeforeDelete(ctx *Context, e Entity) error {
+	if h, ok := e.(BeforeDelete); ok {
+		return abortOnHookError(ctx, h.BeforeDelete(ctx))
+	}
+	return nil
+}
+
+func runAfterDelete(ctx *Context, e Entity) error {
+	if h, ok := e.(AfterDelete); ok {
+		return abortOnHookError(ctx, h.AfterDelete(ctx))
+	}
+	return nil
+}
+
+// abortOnHookError rollback the transaction of the context if the hook failed
+func abortOnHookError(ctx *Context, err error) error {
+	if err == nil || !ctx.Tx {
+		return err
+	}
+	if rbErr := ctx.Rollback(); rbErr != nil {
+		return errors.Join(err, rbErr)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package goje
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errHookFailed = errors.New("hook failed")
+
+type testHookedPost struct {
+	testPost
+	calls []string
+	fail  string
+}
+
+func (p *testHookedPost) hook(name string) error {
+	p.calls = append(p.calls, name)
+	if p.fail == name {
+		return errHookFailed
+	}
+	return nil
+}
+
+func (p *testHookedPost) BeforeInsert(ctx *Context) error { return p.hook("BeforeInsert") }
+func (p *testHookedPost) AfterInsert(ctx *Context) error  { return p.hook("AfterInsert") }
+func (p *testHookedPost) BeforeUpdate(ctx *Context) error { return p.hook("BeforeUpdate") }
+func (p *testHookedPost) AfterUpdate(ctx *Context) error  { return p.hook("AfterUpdate") }
+func (p *testHookedPost) BeforeDelete(ctx *Context) error { return p.hook("BeforeDelete") }
+func (p *testHookedPost) AfterDelete(ctx *Context) error  { return p.hook("AfterDelete") }
+
+func TestHooks(t *testing.T) {
+	db, fake := newFakeDB(t)
+	post := &testHookedPost{testPost: testPost{ctx: MakeHandlerDB(context.Background(), db), ID: 1}}
+
+	if err := Insert(post); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(post); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(post); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BeforeInsert", "AfterInsert", "BeforeUpdate", "AfterUpdate", "BeforeDelete", "AfterDelete"}
+	if len(post.calls) != len(want) {
+		t.Fatalf("hook calls = %v, want %v", post.calls, want)
+	}
+	for i := range want {
+		if post.calls[i] != want[i] {
+			t.Errorf("hook calls = %v, want %v", post.calls, want)
+		}
+	}
+	if log := fake.Log(); len(log) != 3 {
+		t.Errorf("queries = %v, want 3 queries", log)
+	}
+}
+
+func TestHookErrorRollback(t *testing.T) {
+	db, fake := newFakeDB(t)
+	tx, err := MakeTxHandlerDB(context.Background(), db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := &testHookedPost{testPost: testPost{ctx: tx}, fail: "BeforeInsert"}
+	if err := Insert(post); !errors.Is(err, errHookFailed) {
+		t.Fatalf("Insert() error = %v, want %v", err, errHookFailed)
+	}
+
+	want := []string{"BEGIN", "ROLLBACK"}
+	log := fake.Log()
+	if len(log) != len(want) || log[0] != want[0] || log[1] != want[1] {
+		t.Errorf("queries = %v, want %v", log, want)
+	}
+
+	if _, errs := BulkInsert(MakeHandlerDB(context.Background(), db), false, []Entity{post}); len(errs) != 1 || !errors.Is(errs[0], errHookFailed) {
+		t.Errorf("BulkInsert() errors = %v, want %v", errs, errHookFailed)
+	}
+}
@@ -0,0 +1,321 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LogConfig logging of queries, set by DBConfig.Log or ConfigureDBLog for a connection
+type LogConfig struct {
+	// Logger of queries, slog.Default() if it's nil
+	Logger *slog.Logger `json:"-" yaml:"-"`
+	// LogQueries logs all queries at debug level
+	LogQueries bool `json:"LogQueries" yaml:"LogQueries"`
+	// Redact columns that their arguments aren't logged, DefaultRedactColumns if it's nil
+	Redact []string `json:"Redact" yaml:"Redact"`
+
+	// SlowQueryThreshold of the connection, SlowQueryLogTimeout if it's zero, negative disables slow query logs
+	SlowQueryThreshold time.Duration `json:"SlowQueryThreshold" yaml:"SlowQueryThreshold"`
+	// SlowQuerySampling logs 1 in N slow queries, all of them if it's 0 or 1
+	SlowQuerySampling int `json:"SlowQuerySampling" yaml:"SlowQuerySampling"`
+	// ExplainSlowQueries attaches EXPLAIN of slow SELECT queries (out of transactions) to their log
+	ExplainSlowQueries bool `json:"ExplainSlowQueries" yaml:"ExplainSlowQueries"`
+}
+
+// slowQueries counter of sampling for contexts of connections without settings
+var slowQueries atomic.Uint64
+
+// DefaultRedactColumns arguments of columns that contain these names are logged as RedactedValue
+var DefaultRedactColumns = []string{"password", "passwd", "secret", "token"}
+
+// RedactedValue replaces redacted arguments in logs
+const RedactedValue = "[REDACTED]"
+
+// ConfigureDBLog sets logging of the database connection, contexts that make from it later use it
+func ConfigureDBLog(db *sql.DB, config LogConfig) {
+	h := handleFor(db)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.log = config
+}
+
+// SetLogger sets logger of the context, returns the context for chaining
+func (c *Context) SetLogger(logger *slog.Logger) *Context {
+	c.log.Logger = logger
+	return c
+}
+
+// GetLogger returns logger of the context, slog.Default() if it isn't set
+func (c *Context) GetLogger() *slog.Logger {
+	if c.log.Logger != nil {
+		return c.log.Logger
+	}
+	return slog.Default()
+}
+
+// SetSlowQueryThreshold overrides slow query threshold of the connection for the context, negative disables it
+func (c *Context) SetSlowQueryThreshold(threshold time.Duration) *Context {
+	c.log.SlowQueryThreshold = threshold
+	return c
+}
+
+// GetSlowQueryThreshold returns slow query threshold of the context, zero if it's disabled
+func (c *Context) GetSlowQueryThreshold() time.Duration {
+	switch {
+	case c.log.SlowQueryThreshold < 0:
+		return 0
+	case c.log.SlowQueryThreshold > 0:
+		return c.log.SlowQueryThreshold
+	}
+	return SlowQueryLogTimeout
+}
+
+// sampleSlowQuery check the slow query is logged by SlowQuerySampling, the first one of each N is logged
+func (c *Context) sampleSlowQuery() bool {
+	if c.log.SlowQuerySampling <= 1 {
+		return true
+	}
+	counter := c.slowQueries
+	if counter == nil {
+		counter = &slowQueries
+	}
+	return (counter.Add(1)-1)%uint64(c.log.SlowQuerySampling) == 0
+}
+
+// QueryLogger logs queries take more than the slow query threshold of the context at warn level
+// and all queries at debug level if LogQueries is set, arguments of sensitive columns are redacted
+func QueryLogger(next QueryHandler) QueryHandler {
+	return func(ctx *Context, event *QueryEvent) error {
+		err := next(ctx, event)
+
+		level, msg := slog.LevelDebug, "query"
+		threshold := ctx.GetSlowQueryThreshold()
+		slow := threshold > 0 && event.Duration > threshold && ctx.sampleSlowQuery()
+		if slow {
+			level, msg = slog.LevelWarn, "slow query"
+		} else if !ctx.log.LogQueries {
+			return err
+		}
+
+		logger := ctx.GetLogger()
+		std := ctx.Ctx
+		if std == nil {
+			std = context.Background()
+		}
+		if !logger.Enabled(std, level) {
+			return err
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", event.Method),
+			slog.String("operation", event.Operation),
+			slog.String("table", event.Table),
+			slog.Duration("duration", event.Duration),
+			slog.Int64("rows", event.RowsAffected),
+			slog.String("query", event.Query),
+			slog.Any("args", redactArgs(event.Query, event.Args, ctx.log.Redact)),
+		}
+		if ctx.attempt > 1 {
+			attrs = append(attrs, slog.Int("attempt", ctx.attempt))
+		}
+		if event.Err != nil {
+			attrs = append(attrs, slog.String("error", event.Err.Error()))
+		}
+		if slow {
+			attrs = append(attrs, slog.Duration("threshold", threshold))
+			if ctx.log.SlowQuerySampling > 1 {
+				attrs = append(attrs, slog.Int("sampling", ctx.log.SlowQuerySampling))
+			}
+			// a tx can't run EXPLAIN on its connection while rows of the query are open,
+			// out of a tx it waits for rows to release their connection, so a one connection pool doesn't block
+			if ctx.log.ExplainSlowQueries && !ctx.Tx && event.Err == nil && isSelect(event.Query) {
+				event.afterRelease(func() {
+					plan, err := explain(ctx, event.Query, event.Args)
+					if err != nil {
+						attrs = append(attrs, slog.String("explain_error", err.Error()))
+					} else {
+						attrs = append(attrs, slog.Any("explain", plan))
+					}
+					logger.LogAttrs(std, level, msg, attrs...)
+				})
+				return err
+			}
+		}
+		logger.LogAttrs(std, level, msg, attrs...)
+		return err
+	}
+}
+
+// isSelect check the query is a SELECT (or a WITH ... SELECT) that EXPLAIN supports
+func isSelect(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	if len(query) < 6 {
+		return false
+	}
+	prefix := strings.ToUpper(query[:6])
+	return prefix == "SELECT" || strings.HasPrefix(prefix, "WITH ")
+}
+
+// explain runs EXPLAIN of the query on the database of the context (out of the middlewares),
+// returns rows of the plan as "column=value" lines
+func explain(ctx *Context, query string, args []any) ([]string, error) {
+	rows, err := ctx.DB.QueryContext(ctx.Ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []string
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		items := make([]string, 0, len(columns))
+		for i, column := range columns {
+			if values[i].Valid {
+				items = append(items, fmt.Sprintf("%s=%s", column, values[i].String))
+			}
+		}
+		plan = append(plan, strings.Join(items, " "))
+	}
+	return plan, rows.Err()
+}
+
+// redactArgs returns a copy of args, arguments of redact columns (DefaultRedactColumns if it's nil) are replaced
+func redactArgs(query string, args []any, redact []string) []any {
+	if redact == nil {
+		redact = DefaultRedactColumns
+	}
+	if len(args) == 0 || len(redact) == 0 {
+		return args
+	}
+
+	out := append([]any(nil), args...)
+	for i, column := range argColumns(query, len(args)) {
+		column = strings.ToLower(column)
+		for _, r := range redact {
+			if column != "" && strings.Contains(column, strings.ToLower(r)) {
+				out[i] = RedactedValue
+				break
+			}
+		}
+	}
+	return out
+}
+
+// argColumnKeywords don't change the column of next placeholder: `id` NOT IN (?, ?), `age` BETWEEN ? AND ?
+var argColumnKeywords = map[string]bool{
+	"NOT": true, "IN": true, "LIKE": true, "ILIKE": true, "BETWEEN": true, "AND": true, "IS": true, "REGEXP": true,
+}
+
+// argColumns guesses column of each placeholder (`?` or `$n`) of the query:
+// columns of INSERT by their position in VALUES tuples, otherwise the last identifier before the placeholder
+func argColumns(query string, n int) []string {
+	out := make([]string, n)
+
+	var (
+		last        string
+		insert      bool
+		columns     []string
+		inColumns   bool
+		values      bool
+		depth, pos  int
+		placeholder int
+	)
+
+	set := func(index int) {
+		if index < 0 || index >= n {
+			return
+		}
+		if values && depth > 0 && len(columns) > 0 {
+			out[index] = columns[pos%len(columns)]
+			return
+		}
+		out[index] = last
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i) - 1
+		case c == '`' || c == '"':
+			end := skipQuoted(query, i)
+			name := strings.Trim(query[i:end], "`\"")
+			if inColumns {
+				columns = append(columns, name)
+			}
+			last = name
+			i = end - 1
+		case c == '?':
+			set(placeholder)
+			placeholder++
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			index := 0
+			for ; j < len(query) && isDigit(query[j]); j++ {
+				index = index*10 + int(query[j]-'0')
+			}
+			set(index - 1)
+			i = j - 1
+		case c == '(':
+			depth++
+			if depth == 1 {
+				inColumns = insert && !values && columns == nil
+				pos = 0
+			}
+		case c == ')':
+			depth--
+			inColumns = false
+		case c == ',' && depth == 1:
+			pos++
+		case isWordStart(c):
+			j := i
+			for j < len(query) && (isWordStart(query[j]) || isDigit(query[j])) {
+				j++
+			}
+			word := query[i:j]
+			upper := strings.ToUpper(word)
+			switch {
+			case upper == "INSERT" || upper == "REPLACE":
+				insert = true
+			case insert && upper == "VALUES":
+				values = true
+			case upper == "ON":
+				// ON DUPLICATE KEY UPDATE / ON CONFLICT assign by column names
+				values = false
+			}
+			if inColumns {
+				columns = append(columns, word)
+			}
+			if !argColumnKeywords[upper] {
+				last = word
+			}
+			i = j - 1
+		}
+	}
+	return out
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,157 @@
+package goje
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		query string
+		args  []any
+		want  []any
+	}{
+		{
+			"UPDATE `users` SET `name` = ?,`password` = ? WHERE (id = ?)",
+			[]any{"john", "s3cret", 1},
+			[]any{"john", RedactedValue, 1},
+		},
+		{
+			"INSERT INTO `users`(`name`,`password_hash`) VALUES (?,?),(?,?)",
+			[]any{"a", "h1", "b", "h2"},
+			[]any{"a", RedactedValue, "b", RedactedValue},
+		},
+		{
+			`INSERT INTO "users"("api_token","name") VALUES ($1,LOWER($2)) ON CONFLICT DO NOTHING`,
+			[]any{"t", "n"},
+			[]any{RedactedValue, "n"},
+		},
+		{
+			"SELECT `id` FROM `users` WHERE (`users`.`password` NOT IN (?,?) AND name LIKE ?) LIMIT ?",
+			[]any{"x", "y", "jo%", 10},
+			[]any{RedactedValue, RedactedValue, "jo%", 10},
+		},
+		{
+			"SELECT * FROM `users` WHERE note = 'password = ?' AND name = ?",
+			[]any{"john"},
+			[]any{"john"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := redactArgs(tt.query, tt.args, nil); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("redactArgs(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if got := redactArgs("UPDATE t SET `pin` = ?", []any{1234}, []string{"PIN"}); got[0] != RedactedValue {
+		t.Errorf("redactArgs() with custom rules = %v", got)
+	}
+}
+
+func TestQueryLogger(t *testing.T) {
+	db, _ := newFakeDB(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ConfigureDBLog(db, LogConfig{Logger: logger, LogQueries: true})
+
+	handler := MakeHandlerDB(context.Background(), db)
+	if _, err := handler.RawUpdate("users", map[string]any{"password": "s3cret"}, Where("id = ?", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "query" || record["method"] != MethodExec ||
+		record["operation"] != "RawUpdate" || record["table"] != "users" || record["rows"] != float64(0) {
+		t.Errorf("query log = %v", record)
+	}
+	if args := record["args"].([]any); args[0] != RedactedValue || args[1] != float64(1) {
+		t.Errorf("query log args = %v", args)
+	}
+
+	// slow queries are logged at warn level without LogQueries
+	defer func(timeout time.Duration) { SlowQueryLogTimeout = timeout }(SlowQueryLogTimeout)
+	SlowQueryLogTimeout = time.Nanosecond
+	buf.Reset()
+
+	handler = MakeHandlerDB(context.Background(), db).SetLogger(logger)
+	handler.log.LogQueries = false
+	if _, err := handler.Exec("DELETE FROM `users`"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"level":"WARN","msg":"slow query"`) {
+		t.Errorf("slow query log = %s", buf.String())
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.HasPrefix(query, "EXPLAIN ") {
+			return []string{"table", "type", "key"}, [][]driver.Value{{"users", "ALL", nil}}, nil
+		}
+		return []string{"id"}, nil, nil
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	ConfigureDBLog(db, LogConfig{
+		Logger:             logger,
+		SlowQueryThreshold: time.Nanosecond,
+		SlowQuerySampling:  3,
+		ExplainSlowQueries: true,
+	})
+
+	// EXPLAIN waits for the connection of the rows
+	db.SetMaxOpenConns(1)
+	std, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	handler := MakeHandlerDB(std, db)
+	if handler.GetSlowQueryThreshold() != time.Nanosecond {
+		t.Errorf("GetSlowQueryThreshold() = %s", handler.GetSlowQueryThreshold())
+	}
+
+	for range 5 {
+		rows, err := handler.Query("SELECT `id` FROM `users` WHERE `id` > ?", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("sampled slow query logs = %d, want 2\n%s", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["msg"] != "slow query" || record["sampling"] != float64(3) || record["threshold"] == nil {
+		t.Errorf("slow query log = %v", record)
+	}
+	if plan, _ := record["explain"].([]any); len(plan) != 1 || plan[0] != "table=users type=ALL" {
+		t.Errorf("slow query explain = %v", record["explain"])
+	}
+
+	// the context overrides the threshold of the connection
+	buf.Reset()
+	if _, err := MakeHandlerDB(context.Background(), db).SetSlowQueryThreshold(-1).Exec("DELETE FROM `users`"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("disabled slow query log = %s", buf.String())
+	}
+}
@@ -0,0 +1,148 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// queryTracker running queries (until their rows are closed) and transactions (until Commit/Rollback) of a connection, Managed.Shutdown waits for them
+type queryTracker struct {
+	running atomic.Int64
+	closing atomic.Bool
+}
+
+// start a query, false if the connection is shutting down
+func (t *queryTracker) start() bool {
+	t.running.Add(1)
+	if t.closing.Load() {
+		t.running.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (t *queryTracker) done() {
+	t.running.Add(-1)
+}
+
+// wait for running queries until ctx is done
+func (t *queryTracker) wait(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for t.running.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Managed a database or a cluster with readiness checks, health monitor of replicas and graceful shutdown
+//
+//	m, err := goje.InitManagedDB(conf)
+//	m.Monitor(5*time.Second, time.Second)
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//		if err := m.Ping(r.Context()); err != nil {
+//			w.WriteHeader(http.StatusServiceUnavailable)
+//		}
+//	})
+//	defer m.Shutdown(shutdownCtx)
+type Managed struct {
+	DB *sql.DB
+	// Cluster of the DB if it's made by ManageCluster
+	Cluster *Cluster
+
+	queries  *queryTracker
+	stop     chan struct{}
+	monitor  sync.WaitGroup
+	shutdown sync.Once
+	err      error
+}
+
+// Manage tracks queries of the connection, handlers made from it after Shutdown fail by ErrShuttingDown
+func Manage(db *sql.DB) *Managed {
+	return &Managed{DB: db, queries: &handleFor(db).queries, stop: make(chan struct{})}
+}
+
+// ManageCluster tracks queries of the cluster primary, Monitor checks its replicas
+func ManageCluster(cluster *Cluster) *Managed {
+	m := Manage(cluster.Primary)
+	m.Cluster = cluster
+	return m
+}
+
+// InitManagedDB connects to the default database like InitDB and manages it
+func InitManagedDB(conn *DBConfig) (*Managed, error) {
+	if err := InitDB(conn); err != nil {
+		return nil, err
+	}
+	return Manage(DefatultDB), nil
+}
+
+// InitManagedCluster connects to the cluster like InitCluster and manages it
+func InitManagedCluster(conf *ClusterConfig) (*Managed, error) {
+	cluster, err := InitCluster(conf)
+	if err != nil {
+		return nil, err
+	}
+	return ManageCluster(cluster), nil
+}
+
+// Ping readiness of the database, ErrShuttingDown after Shutdown is called
+func (m *Managed) Ping(ctx context.Context) error {
+	if m.queries.closing.Load() {
+		return ErrShuttingDown
+	}
+	return m.DB.PingContext(ctx)
+}
+
+// Monitor checks replicas of the cluster every interval in background, each check has the timeout,
+// unhealthy replicas don't receive reads until they pass a check. It stops on Shutdown
+func (m *Managed) Monitor(interval, timeout time.Duration) {
+	if m.Cluster == nil || len(m.Cluster.Replicas) == 0 {
+		return
+	}
+
+	m.monitor.Add(1)
+	go func() {
+		defer m.monitor.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				m.Cluster.CheckReplicas(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// Shutdown rejects new queries and transactions, waits for running goje queries (until their rows are closed)
+// and open transactions (their statements still run), then closes the connections.
+// If ctx is done before queries finish, connections are closed anyway and ctx error is returned
+func (m *Managed) Shutdown(ctx context.Context) error {
+	m.shutdown.Do(func() {
+		m.queries.closing.Store(true)
+		close(m.stop)
+		m.monitor.Wait()
+
+		errs := []error{m.queries.wait(ctx)}
+		if m.Cluster != nil {
+			errs = append(errs, m.Cluster.Close())
+		} else {
+			errs = append(errs, CloseDB(m.DB))
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
@@ -0,0 +1,157 @@
+package goje
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManagedShutdown(t *testing.T) {
+	db, fake := newFakeDB(t)
+	m := Manage(db)
+	handler := MakeHandlerDB(context.Background(), db)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		close(started)
+		<-release
+		return driver.RowsAffected(1), nil
+	}
+
+	queryErr := make(chan error, 1)
+	go func() {
+		_, err := handler.Exec("DELETE FROM `users`")
+		queryErr <- err
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- m.Shutdown(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned before the running query: %v", err)
+	default:
+	}
+	if err := m.Ping(context.Background()); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Ping() error = %v, want %v", err, ErrShuttingDown)
+	}
+	if _, err := handler.Exec("DELETE FROM `users`"); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Exec() error = %v, want %v", err, ErrShuttingDown)
+	}
+
+	close(release)
+	if err := <-queryErr; err != nil {
+		t.Errorf("running query error = %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if db.Ping() == nil {
+		t.Errorf("Shutdown() didn't close the database")
+	}
+}
+
+func TestManagedShutdownTimeout(t *testing.T) {
+	db, fake := newFakeDB(t)
+	m := Manage(db)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		close(started)
+		<-release
+		return driver.RowsAffected(1), nil
+	}
+	go MakeHandlerDB(context.Background(), db).Exec("DELETE FROM `users`")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestManagedShutdownWaitsForTxAndRows(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}, {int64(2)}}, nil
+	}
+	m := Manage(db)
+	handler := MakeHandlerDB(context.Background(), db)
+
+	tx, err := handler.Begin(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := handler.Query("SELECT `id` FROM `users`")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- m.Shutdown(context.Background()) }()
+	waitShutdown := func(step string) {
+		time.Sleep(20 * time.Millisecond)
+		select {
+		case err := <-shutdown:
+			t.Fatalf("Shutdown() returned before %s: %v", step, err)
+		default:
+		}
+	}
+
+	waitShutdown("the transaction ends")
+	if _, err := tx.Exec("DELETE FROM `users`"); err != nil {
+		t.Errorf("statement of a running transaction error = %v", err)
+	}
+	if _, err := handler.Begin(nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Begin() error = %v, want %v", err, ErrShuttingDown)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitShutdown("rows are closed")
+	for rows.Next() {
+	}
+	rows.Close()
+
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestClusterHealth(t *testing.T) {
+	cluster, fakes := testCluster(t, BalanceRoundRobin)
+	m := ManageCluster(cluster)
+
+	if err := m.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fakes[1].mu.Lock()
+	fakes[1].pingErr = errors.New("connection refused")
+	fakes[1].mu.Unlock()
+	if err := cluster.CheckReplicas(context.Background()); err == nil {
+		t.Fatal("CheckReplicas() didn't fail by the unhealthy replica")
+	}
+	for range 3 {
+		if got := cluster.Replica(); got != cluster.Replicas[1] {
+			t.Fatalf("Replica() returned the unhealthy replica")
+		}
+	}
+
+	fakes[1].mu.Lock()
+	fakes[1].pingErr = nil
+	fakes[1].mu.Unlock()
+	if err := cluster.CheckReplicas(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(cluster.HealthyReplicas()); got != 2 {
+		t.Errorf("HealthyReplicas() = %d replicas, want 2", got)
+	}
+}
@@ -0,0 +1,115 @@
+package goje
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// QueryMetric a finished query that MetricsCollector observes
+type QueryMetric struct {
+	// Database name of the connection (DBConfig.Name), empty for connections that aren't opened by NewDBConnection
+	Database string
+	// Operation SQL operation of the query: SELECT, INSERT, UPDATE ...
+	Operation string
+	// Table of the goje operation, empty if it's unknown
+	Table    string
+	Duration time.Duration
+	Err      error
+	// ErrorCode of Err by ErrorCode
+	ErrorCode string
+}
+
+// MetricsCollector receives metrics of queries, set it by SetMetricsCollector
+// pool gauges are pulled by PoolStats, the collector reads them when it's scraped
+type MetricsCollector interface {
+	ObserveQuery(metric QueryMetric)
+}
+
+type metricsHolder struct {
+	collector MetricsCollector
+}
+
+var metricsCollector atomic.Pointer[metricsHolder]
+
+// SetMetricsCollector sets collector of query metrics of all contexts, nil disables it
+func SetMetricsCollector(collector MetricsCollector) {
+	if collector == nil {
+		metricsCollector.Store(nil)
+		return
+	}
+	metricsCollector.Store(&metricsHolder{collector: collector})
+}
+
+// QueryMetrics sends metrics of queries to the collector of SetMetricsCollector
+func QueryMetrics(next QueryHandler) QueryHandler {
+	return func(ctx *Context, event *QueryEvent) error {
+		err := next(ctx, event)
+
+		holder := metricsCollector.Load()
+		if holder == nil {
+			return err
+		}
+		holder.collector.ObserveQuery(QueryMetric{
+			Database:  ctx.dbName,
+			Operation: QueryOperation(event.Query),
+			Table:     event.Table,
+			Duration:  event.Duration,
+			Err:       err,
+			ErrorCode: ErrorCode(err),
+		})
+		return err
+	}
+}
+
+// QueryOperation returns the first keyword of the query in upper case: SELECT, INSERT, UPDATE ...
+func QueryOperation(query string) string {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	if i := strings.IndexAny(query, " \t\r\n("); i >= 0 {
+		query = query[:i]
+	}
+	return strings.ToUpper(query)
+}
+
+// ErrorCode returns code of a database error: MySQL error number, SQLSTATE of drivers that expose SQLState() (pgx),
+// "" for nil and "unknown" for other errors
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return strconv.Itoa(int(mysqlErr.Number))
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return "unknown"
+}
+
+// PoolStats returns sql.DB.Stats() of connections opened by NewDBConnection by their names (DBConfig.Name)
+// connections with the same name overwrite each other, give them unique names (NewCluster names them
+// <name>/primary and <name>/replica-N), close them by CloseDB to drop them from the stats
+func PoolStats() map[string]sql.DBStats {
+	out := map[string]sql.DBStats{}
+	dbHandles.Range(func(key, value any) bool {
+		h := value.(*dbHandle)
+		h.mu.RLock()
+		name, pooled := h.name, h.pooled
+		h.mu.RUnlock()
+
+		if pooled {
+			out[name] = key.(*sql.DB).Stats()
+		}
+		return true
+	})
+	return out
+}
@@ -0,0 +1,126 @@
+package goje
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type testCollector struct {
+	mu      sync.Mutex
+	metrics []QueryMetric
+}
+
+func (c *testCollector) ObserveQuery(metric QueryMetric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = append(c.metrics, metric)
+}
+
+func TestQueryMetrics(t *testing.T) {
+	db, fake := newFakeDB(t)
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return nil, duplicate
+	}
+
+	collector := &testCollector{}
+	SetMetricsCollector(collector)
+	defer SetMetricsCollector(nil)
+
+	handler := MakeHandlerDB(context.Background(), db)
+	handler.dbName = "main"
+	if _, err := handler.RawBulkInsert("users", []map[string]any{{"name": "john"}}); !errors.Is(err, duplicate) {
+		t.Fatalf("RawBulkInsert() error = %v", err)
+	}
+	if _, err := handler.Query("select 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collector.metrics) != 2 {
+		t.Fatalf("metrics = %d, want 2", len(collector.metrics))
+	}
+	insert, sel := collector.metrics[0], collector.metrics[1]
+	if insert.Database != "main" || insert.Operation != "INSERT" || insert.Table != "users" || insert.ErrorCode != "1062" || insert.Duration <= 0 {
+		t.Errorf("insert metric = %+v", insert)
+	}
+	if sel.Operation != "SELECT" || sel.Table != "" || sel.Err != nil || sel.ErrorCode != "" {
+		t.Errorf("select metric = %+v", sel)
+	}
+}
+
+func TestQueryOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT `id` FROM `users`":       "SELECT",
+		"  insert into t values (1)":     "INSERT",
+		"(SELECT 1) UNION (SELECT 2)":    "SELECT",
+		"WITH x AS (SELECT 1) SELECT * ": "WITH",
+		"":                               "",
+	}
+	for query, want := range tests {
+		if got := QueryOperation(query); got != want {
+			t.Errorf("QueryOperation(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{&mysql.MySQLError{Number: 1213}, "1213"},
+		{sqlStateErr("23505"), "23505"},
+		{errors.New("oops"), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	RegisterDialect("goje_fake", SQLiteDialect{})
+	db, err := NewDBConnection(&DBConfig{Driver: "goje_fake", Name: "reports", Path: "reports.db", MaxOpenConns: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stats, ok := PoolStats()["reports"]
+	if !ok || stats.MaxOpenConnections != 7 {
+		t.Errorf("PoolStats() = %+v", PoolStats())
+	}
+}
+
+func TestPoolStatsCluster(t *testing.T) {
+	RegisterDialect("goje_fake", SQLiteDialect{})
+	conf := DBConfig{Driver: "goje_fake", Path: "shop.db"}
+	cluster, err := NewCluster(&ClusterConfig{Primary: conf, Replicas: []DBConfig{conf, conf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := PoolStats()
+	for _, name := range []string{"shop.db/primary", "shop.db/replica-1", "shop.db/replica-2"} {
+		if _, ok := stats[name]; !ok {
+			t.Errorf("PoolStats() hasn't %s: %v", name, stats)
+		}
+	}
+
+	if err := cluster.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name := range PoolStats() {
+		if strings.HasPrefix(name, "shop.db/") {
+			t.Errorf("PoolStats() has %s of a closed connection", name)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package goje
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Methods of QueryEvent
+const (
+	MethodExec     = "Exec"
+	MethodQuery    = "Query"
+	MethodQueryRow = "QueryRow"
+)
+
+// QueryEvent a query that runs through the middlewares of the context
+// fields after Start are filled by the database call, middlewares read them after next returns
+type QueryEvent struct {
+	// Method is MethodExec, MethodQuery or MethodQueryRow
+	Method string
+	// Operation of goje that runs the query (RawDelete, Select, Insert ...), empty for Context.Exec/Query/QueryRow
+	Operation string
+	// Table of the operation, empty if it's unknown
+	Table string
+	Query string
+	Args  []any
+
+	Start    time.Time
+	Duration time.Duration
+	// RowsAffected of Exec, -1 for queries or if the driver doesn't support it
+	RowsAffected int64
+	Err          error
+
+	// Result of the database call by Method
+	Result sql.Result
+	Rows   *sql.Rows
+	Row    *sql.Row
+
+	// released callbacks wait for the connection of Rows or Row
+	released []func()
+}
+
+// afterRelease runs f when the query releases its connection:
+// right away for Exec, on Close of Rows or Scan of Row for queries
+func (e *QueryEvent) afterRelease(f func()) {
+	if e.Method == MethodExec {
+		f()
+		return
+	}
+	e.released = append(e.released, f)
+}
+
+// release returns done of the query that runs the released callbacks first
+func (e *QueryEvent) release(done func()) func() {
+	if len(e.released) == 0 {
+		return done
+	}
+	return sync.OnceFunc(func() {
+		for _, f := range e.released {
+			f()
+		}
+		done()
+	})
+}
+
+// QueryHandler runs the query of the event
+type QueryHandler func(ctx *Context, event *QueryEvent) error
+
+// Middleware wraps a QueryHandler, it can act before or after next, change the event or stop the query by an error
+//
+//	func Audit(next goje.QueryHandler) goje.QueryHandler {
+//		return func(ctx *goje.Context, event *goje.QueryEvent) error {
+//			err := next(ctx, event)
+//			if event.Method == goje.MethodExec {
+//				audit.Record(event.Query, event.Args, event.RowsAffected, err)
+//			}
+//			return err
+//		}
+//	}
+type Middleware func(next QueryHandler) QueryHandler
+
+// DefaultMiddlewares run for every context before middlewares of the connection and the context
+var DefaultMiddlewares = []Middleware{QueryLogger, QueryMetrics}
+
+// UseDB adds middlewares to the database connection, contexts that make from it later run them
+func UseDB(db *sql.DB, middlewares ...Middleware) {
+	h := handleFor(db)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.middlewares = append(h.middlewares, middlewares...)
+}
+
+// Use adds middlewares to the context, returns the context for chaining
+func (c *Context) Use(middlewares ...Middleware) *Context {
+	c.middlewares = append(c.middlewares[:len(c.middlewares):len(c.middlewares)], middlewares...)
+	return c
+}
+
+// Exec runs a statement through the middlewares
+func (c *Context) Exec(query string, args ...any) (sql.Result, error) {
+	return c.exec("", "", query, args)
+}
+
+// Query runs a query through the middlewares, the query is running (for Managed.Shutdown) until rows are closed
+func (c *Context) Query(query string, args ...any) (*Rows, error) {
+	return c.query("", "", query, args)
+}
+
+// QueryRow runs a single row query through the middlewares
+func (c *Context) QueryRow(query string, args ...any) *Row {
+	return c.queryRow("", "", query, args)
+}
+
+func (c *Context) exec(operation, table, query string, args []any) (sql.Result, error) {
+	done, err := c.track()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	event := &QueryEvent{Method: MethodExec, Operation: operation, Table: table, Query: query, Args: args}
+	err = c.run(event)
+	if err == nil && event.Result == nil {
+		err = ErrQueryDidntRun
+	}
+	return event.Result, err
+}
+
+func (c *Context) query(operation, table, query string, args []any) (*Rows, error) {
+	done, err := c.track()
+	if err != nil {
+		return nil, err
+	}
+
+	event := &QueryEvent{Method: MethodQuery, Operation: operation, Table: table, Query: query, Args: args}
+	err = c.run(event)
+	done = event.release(done)
+	if err == nil && event.Rows == nil {
+		err = ErrQueryDidntRun
+	}
+	if err != nil {
+		if event.Rows != nil {
+			event.Rows.Close()
+		}
+		done()
+		return nil, err
+	}
+	return &Rows{Rows: event.Rows, done: done}, nil
+}
+
+func (c *Context) queryRow(operation, table, query string, args []any) *Row {
+	done, err := c.track()
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	event := &QueryEvent{Method: MethodQueryRow, Operation: operation, Table: table, Query: query, Args: args}
+	err = c.run(event)
+	done = event.release(done)
+	if err == nil && event.Row == nil {
+		err = ErrQueryDidntRun
+	}
+	if err != nil {
+		done()
+	}
+	return &Row{row: event.Row, err: err, done: done}
+}
+
+// track counts a query as running until done is called, queries of a transaction are counted by the transaction
+func (c *Context) track() (done func(), err error) {
+	if c.queries == nil || c.Tx {
+		return func() {}, nil
+	}
+	if !c.queries.start() {
+		return nil, ErrShuttingDown
+	}
+	return sync.OnceFunc(c.queries.done), nil
+}
+
+// Rows result of Query, Close it to release the connection
+type Rows struct {
+	*sql.Rows
+	done func()
+}
+
+// Close closes the rows, the query isn't running anymore
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.done()
+	return err
+}
+
+// Row result of QueryRow, like sql.Row it keeps the error of the query (or a middleware) until Scan
+type Row struct {
+	row  *sql.Row
+	err  error
+	done func()
+}
+
+// Scan copies columns of the row into dest, returns sql.ErrNoRows if there isn't any row
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.done()
+	return r.row.Scan(dest...)
+}
+
+// Err returns the error of the query without scanning
+func (r *Row) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Err()
+}
+
+// run the event through middlewares of the context
+func (c *Context) run(event *QueryEvent) error {
+	event.RowsAffected = -1
+	event.Start = time.Now()
+
+	handler := QueryHandler(runQuery)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = keepErr(c.middlewares[i](handler))
+	}
+	for i := len(DefaultMiddlewares) - 1; i >= 0; i-- {
+		handler = keepErr(DefaultMiddlewares[i](handler))
+	}
+	return handler(c, event)
+}
+
+// keepErr sets error of a middleware to the event, so outer middlewares see it
+func keepErr(handler QueryHandler) QueryHandler {
+	return func(ctx *Context, event *QueryEvent) error {
+		err := handler(ctx, event)
+		if err != nil && event.Err == nil {
+			event.Err = err
+		}
+		return err
+	}
+}
+
+// runQuery the last handler of the chain, calls the database
+func runQuery(c *Context, event *QueryEvent) error {
+	switch event.Method {
+	case MethodExec:
+		event.Result, event.Err = c.DB.ExecContext(c.Ctx, event.Query, event.Args...)
+		if event.Err == nil {
+			if affected, err := event.Result.RowsAffected(); err == nil {
+				event.RowsAffected = affected
+			}
+		}
+	case MethodQuery:
+		event.Rows, event.Err = c.DB.QueryContext(c.Ctx, event.Query, event.Args...)
+	case MethodQueryRow:
+		event.Row = c.DB.QueryRowContext(c.Ctx, event.Query, event.Args...)
+		event.Err = event.Row.Err()
+	}
+	event.Duration = time.Since(event.Start)
+	return event.Err
+}
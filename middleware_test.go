@@ -0,0 +1,129 @@
+package goje
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recordMiddleware appends name to calls before and after next
+func recordMiddleware(name string, calls *[]string, events *[]QueryEvent) Middleware {
+	return func(next QueryHandler) QueryHandler {
+		return func(ctx *Context, event *QueryEvent) error {
+			*calls = append(*calls, name+">")
+			err := next(ctx, event)
+			*calls = append(*calls, "<"+name)
+			if events != nil {
+				*events = append(*events, *event)
+			}
+			return err
+		}
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		return driver.RowsAffected(3), nil
+	}
+
+	var calls []string
+	var events []QueryEvent
+	UseDB(db, recordMiddleware("db", &calls, nil))
+	handler := MakeHandlerDB(context.Background(), db).Use(recordMiddleware("ctx", &calls, &events))
+
+	if _, err := handler.RawDelete("posts", []QueryInterface{Where("id = ?", 7)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"db>", "ctx>", "<ctx", "<db"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+	event := events[0]
+	if event.Method != MethodExec || event.Operation != "RawDelete" || event.Table != "posts" ||
+		event.Query != "DELETE FROM `posts`  WHERE (id = ?)" || !reflect.DeepEqual(event.Args, []any{7}) ||
+		event.RowsAffected != 3 || event.Err != nil || event.Duration <= 0 {
+		t.Errorf("event = %+v", event)
+	}
+
+	// transactions and later handlers of the connection inherit its middlewares
+	calls = nil
+	err := WithTxDB(context.Background(), db, nil, func(tx *Context) error {
+		_, err := tx.Exec("UPDATE `posts` SET `title` = ?", "x")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db>", "<db"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("tx middleware calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	db, fake := newFakeDB(t)
+	errDenied := errors.New("denied")
+
+	var events []QueryEvent
+	var calls []string
+	handler := MakeHandlerDB(context.Background(), db).Use(
+		recordMiddleware("outer", &calls, &events),
+		func(next QueryHandler) QueryHandler {
+			return func(ctx *Context, event *QueryEvent) error {
+				if event.Method != MethodExec {
+					return next(ctx, event)
+				}
+				return errDenied
+			}
+		},
+	)
+
+	if _, err := handler.Exec("DELETE FROM `posts`"); !errors.Is(err, errDenied) {
+		t.Errorf("Exec() error = %v, want %v", err, errDenied)
+	}
+	if !errors.Is(events[0].Err, errDenied) {
+		t.Errorf("event error = %v, want %v", events[0].Err, errDenied)
+	}
+	if len(fake.Log()) != 0 {
+		t.Errorf("stopped query ran: %v", fake.Log())
+	}
+
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"n"}, [][]driver.Value{{int64(5)}}, nil
+	}
+	var n int
+	if err := handler.QueryRow("SELECT COUNT(*) FROM `posts`").Scan(&n); err != nil || n != 5 {
+		t.Errorf("QueryRow() = %d, %v", n, err)
+	}
+	if events[1].Method != MethodQueryRow || events[1].Operation != "" {
+		t.Errorf("event = %+v", events[1])
+	}
+}
+
+func TestMiddlewareSkipsQuery(t *testing.T) {
+	db, fake := newFakeDB(t)
+	handler := MakeHandlerDB(context.Background(), db).Use(func(next QueryHandler) QueryHandler {
+		return func(ctx *Context, event *QueryEvent) error {
+			return nil
+		}
+	})
+
+	if _, err := handler.Exec("DELETE FROM `posts`"); !errors.Is(err, ErrQueryDidntRun) {
+		t.Errorf("Exec() error = %v, want %v", err, ErrQueryDidntRun)
+	}
+	if _, err := handler.Query("SELECT 1"); !errors.Is(err, ErrQueryDidntRun) {
+		t.Errorf("Query() error = %v, want %v", err, ErrQueryDidntRun)
+	}
+	var n int
+	if err := handler.QueryRow("SELECT 1").Scan(&n); !errors.Is(err, ErrQueryDidntRun) {
+		t.Errorf("QueryRow() error = %v, want %v", err, ErrQueryDidntRun)
+	}
+	if _, err := Select[testUser](handler, "users"); !errors.Is(err, ErrQueryDidntRun) {
+		t.Errorf("Select() error = %v, want %v", err, ErrQueryDidntRun)
+	}
+	if len(fake.Log()) != 0 {
+		t.Errorf("skipped queries ran: %v", fake.Log())
+	}
+}
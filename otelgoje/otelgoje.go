@@ -0,0 +1,137 @@
+// Package otelgoje traces goje queries by OpenTelemetry, a span per query
+//
+//	goje.UseDB(db, otelgoje.Middleware())
+//	// or for every context
+//	goje.DefaultMiddlewares = append(goje.DefaultMiddlewares, otelgoje.Middleware())
+package otelgoje
+
+import (
+	"context"
+
+	"github.com/genigo/goje"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName instrumentation scope of the tracer
+const ScopeName = "github.com/genigo/goje/otelgoje"
+
+// Attribute keys of spans
+const (
+	DBSystem       = attribute.Key("db.system")
+	DBStatement    = attribute.Key("db.statement")
+	DBOperation    = attribute.Key("db.operation")
+	DBTable        = attribute.Key("db.sql.table")
+	DBRowsAffected = attribute.Key("db.rows_affected")
+	GojeOperation  = attribute.Key("goje.operation")
+	GojeAttempt    = attribute.Key("goje.tx.attempt")
+)
+
+// Option of Middleware
+type Option func(*config)
+
+type config struct {
+	provider   trace.TracerProvider
+	attributes []attribute.KeyValue
+}
+
+// WithTracerProvider sets tracer provider of spans, otel.GetTracerProvider() by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithAttributes adds attributes to all spans, e.g. db.name
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// Middleware makes a goje middleware that runs each query in a client span, child of the span of Context.Ctx
+func Middleware(options ...Option) goje.Middleware {
+	c := config{}
+	for _, option := range options {
+		option(&c)
+	}
+	if c.provider == nil {
+		c.provider = otel.GetTracerProvider()
+	}
+	tracer := c.provider.Tracer(ScopeName)
+
+	return func(next goje.QueryHandler) goje.QueryHandler {
+		return func(ctx *goje.Context, event *goje.QueryEvent) error {
+			parent := ctx.Ctx
+			if parent == nil {
+				parent = context.Background()
+			}
+
+			operation := Operation(event.Query)
+			attributes := append([]attribute.KeyValue{
+				DBSystem.String(System(ctx.GetDialect())),
+				DBStatement.String(event.Query),
+				DBOperation.String(operation),
+			}, c.attributes...)
+			if event.Table != "" {
+				attributes = append(attributes, DBTable.String(event.Table))
+			}
+			if event.Operation != "" {
+				attributes = append(attributes, GojeOperation.String(event.Operation))
+			}
+			if ctx.Attempt() > 1 {
+				attributes = append(attributes, GojeAttempt.Int(ctx.Attempt()))
+			}
+
+			spanCtx, span := tracer.Start(parent, spanName(operation, event.Table),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes...),
+			)
+			defer span.End()
+
+			// the driver gets the span context
+			inner := *ctx
+			inner.Ctx = spanCtx
+			err := next(&inner, event)
+
+			if event.RowsAffected >= 0 {
+				span.SetAttributes(DBRowsAffected.Int64(event.RowsAffected))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// Operation returns the first keyword of the query: SELECT, INSERT, UPDATE ..., same as goje.QueryOperation
+func Operation(query string) string {
+	return goje.QueryOperation(query)
+}
+
+// System returns db.system value of the dialect
+func System(dialect goje.Dialect) string {
+	if dialect == nil {
+		return "other_sql"
+	}
+	switch name := dialect.Name(); name {
+	case "postgres":
+		return "postgresql"
+	default:
+		return name
+	}
+}
+
+func spanName(operation, table string) string {
+	if operation == "" {
+		operation = "query"
+	}
+	if table == "" {
+		return operation
+	}
+	return operation + " " + table
+}
@@ -0,0 +1,120 @@
+package otelgoje
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genigo/goje"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	errQuery := errors.New("table doesn't exist")
+
+	tests := []struct {
+		name      string
+		event     goje.QueryEvent
+		rows      int64
+		err       error
+		wantName  string
+		wantAttrs map[attribute.Key]attribute.Value
+	}{
+		{
+			name:     "update",
+			event:    goje.QueryEvent{Method: goje.MethodExec, Operation: "RawUpdate", Table: "users", Query: `UPDATE "users" SET "name" = $1`, Args: []any{"secret"}},
+			rows:     2,
+			wantName: "UPDATE users",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				DBSystem:       attribute.StringValue("postgresql"),
+				DBStatement:    attribute.StringValue(`UPDATE "users" SET "name" = $1`),
+				DBOperation:    attribute.StringValue("UPDATE"),
+				DBTable:        attribute.StringValue("users"),
+				DBRowsAffected: attribute.Int64Value(2),
+				GojeOperation:  attribute.StringValue("RawUpdate"),
+			},
+		},
+		{
+			name:     "failed query",
+			event:    goje.QueryEvent{Method: goje.MethodQuery, Query: "select 1 from missing"},
+			rows:     -1,
+			err:      errQuery,
+			wantName: "SELECT",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				DBOperation: attribute.StringValue("SELECT"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+			handler := &goje.Context{Ctx: ctx, Dialect: goje.PostgresDialect{}}
+
+			var driverSpan trace.SpanContext
+			next := func(ctx *goje.Context, event *goje.QueryEvent) error {
+				driverSpan = trace.SpanContextFromContext(ctx.Ctx)
+				event.RowsAffected = tt.rows
+				return tt.err
+			}
+
+			event := tt.event
+			if err := Middleware(WithTracerProvider(provider))(next)(handler, &event); !errors.Is(err, tt.err) {
+				t.Errorf("middleware error = %v, want %v", err, tt.err)
+			}
+			parent.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("spans = %d, want 2", len(spans))
+			}
+			span := spans[0]
+			if span.Name != tt.wantName || span.SpanKind != trace.SpanKindClient {
+				t.Errorf("span = %s (%s), want %s", span.Name, span.SpanKind, tt.wantName)
+			}
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() || driverSpan.SpanID() != span.SpanContext.SpanID() {
+				t.Errorf("span isn't a child of the context span or isn't passed to next")
+			}
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			for key, want := range tt.wantAttrs {
+				if attrs[key] != want {
+					t.Errorf("attribute %s = %v, want %v", key, attrs[key].Emit(), want.Emit())
+				}
+			}
+			if _, ok := attrs[DBRowsAffected]; tt.rows < 0 && ok {
+				t.Errorf("rows affected of a query is set")
+			}
+
+			if tt.err != nil && (span.Status.Code != codes.Error || len(span.Events) == 0 || span.Events[0].Name != "exception") {
+				t.Errorf("error isn't recorded: status = %v, events = %v", span.Status, span.Events)
+			}
+		})
+	}
+}
+
+func TestOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT `id` FROM `users`":       "SELECT",
+		"  insert into t values (1)":     "INSERT",
+		"(SELECT 1) UNION (SELECT 2)":    "SELECT",
+		"WITH x AS (SELECT 1) SELECT * ": "WITH",
+		"":                               "",
+	}
+	for query, want := range tests {
+		if got := Operation(query); got != want {
+			t.Errorf("Operation(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package promgoje exports goje metrics to Prometheus
+//
+//	collector := promgoje.New()
+//	prometheus.MustRegister(collector)
+//	goje.SetMetricsCollector(collector)
+package promgoje
+
+import (
+	"github.com/genigo/goje"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options of the collector
+type Options struct {
+	// Namespace of metric names, "goje" if it's empty
+	Namespace string
+	// Buckets of the query duration histogram in seconds, prometheus.DefBuckets if it's empty
+	Buckets []float64
+}
+
+// Collector a goje.MetricsCollector and a prometheus.Collector:
+// query durations and errors by operation and table, and pool stats of connections opened by goje.NewDBConnection
+type Collector struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+
+	openConnections   *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	maxOpen           *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+var (
+	_ goje.MetricsCollector = (*Collector)(nil)
+	_ prometheus.Collector  = (*Collector)(nil)
+)
+
+// New makes a collector, register it to prometheus and set it by goje.SetMetricsCollector
+func New(options ...Options) *Collector {
+	opts := Options{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = "goje"
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	pool := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(opts.Namespace, "db", name), help, []string{"db"}, nil)
+	}
+
+	return &Collector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Duration of queries by operation and table.",
+			Buckets:   opts.Buckets,
+		}, []string{"db", "operation", "table"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "query_errors_total",
+			Help:      "Failed queries by operation, table and database error code.",
+		}, []string{"db", "operation", "table", "code"}),
+
+		openConnections:   pool("open_connections", "Established connections both in use and idle."),
+		inUse:             pool("in_use_connections", "Connections currently in use."),
+		idle:              pool("idle_connections", "Idle connections."),
+		maxOpen:           pool("max_open_connections", "Maximum open connections, 0 is unlimited."),
+		waitCount:         pool("wait_count_total", "Connections waited for."),
+		waitDuration:      pool("wait_duration_seconds_total", "Time blocked waiting for a new connection."),
+		maxIdleClosed:     pool("max_idle_closed_total", "Connections closed by SetMaxIdleConns."),
+		maxIdleTimeClosed: pool("max_idle_time_closed_total", "Connections closed by SetConnMaxIdleTime."),
+		maxLifetimeClosed: pool("max_lifetime_closed_total", "Connections closed by SetConnMaxLifetime."),
+	}
+}
+
+// ObserveQuery implements goje.MetricsCollector
+func (c *Collector) ObserveQuery(metric goje.QueryMetric) {
+	c.duration.WithLabelValues(metric.Database, metric.Operation, metric.Table).Observe(metric.Duration.Seconds())
+	if metric.Err != nil {
+		c.errors.WithLabelValues(metric.Database, metric.Operation, metric.Table, metric.ErrorCode).Inc()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	for _, desc := range []*prometheus.Desc{
+		c.openConnections, c.inUse, c.idle, c.maxOpen, c.waitCount, c.waitDuration,
+		c.maxIdleClosed, c.maxIdleTimeClosed, c.maxLifetimeClosed,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector, pool stats are read by goje.PoolStats on each scrape
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+
+	for name, stats := range goje.PoolStats() {
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, name)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, name)
+		}
+
+		gauge(c.openConnections, float64(stats.OpenConnections))
+		gauge(c.inUse, float64(stats.InUse))
+		gauge(c.idle, float64(stats.Idle))
+		gauge(c.maxOpen, float64(stats.MaxOpenConnections))
+		counter(c.waitCount, float64(stats.WaitCount))
+		counter(c.waitDuration, stats.WaitDuration.Seconds())
+		counter(c.maxIdleClosed, float64(stats.MaxIdleClosed))
+		counter(c.maxIdleTimeClosed, float64(stats.MaxIdleTimeClosed))
+		counter(c.maxLifetimeClosed, float64(stats.MaxLifetimeClosed))
+	}
+}
@@ -0,0 +1,72 @@
+package promgoje
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/genigo/goje"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type nopDriver struct{}
+
+func (nopDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("no database")
+}
+
+func init() {
+	sql.Register("promgoje_test", nopDriver{})
+	goje.RegisterDialect("promgoje_test", goje.SQLiteDialect{})
+}
+
+func TestCollector(t *testing.T) {
+	db, err := goje.NewDBConnection(&goje.DBConfig{Driver: "promgoje_test", Name: "orders", Path: "orders.db", MaxOpenConns: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	collector := New(Options{Buckets: []float64{0.01, 0.1, 1}})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	collector.ObserveQuery(goje.QueryMetric{Database: "orders", Operation: "SELECT", Table: "orders", Duration: 5 * time.Millisecond})
+	collector.ObserveQuery(goje.QueryMetric{Database: "orders", Operation: "INSERT", Table: "orders", Duration: 50 * time.Millisecond, Err: errors.New("dup"), ErrorCode: "1062"})
+
+	if got := testutil.ToFloat64(collector.errors.WithLabelValues("orders", "INSERT", "orders", "1062")); got != 1 {
+		t.Errorf("errors counter = %v, want 1", got)
+	}
+
+	expected := `
+# HELP goje_db_max_open_connections Maximum open connections, 0 is unlimited.
+# TYPE goje_db_max_open_connections gauge
+goje_db_max_open_connections{db="orders"} 12
+# HELP goje_query_duration_seconds Duration of queries by operation and table.
+# TYPE goje_query_duration_seconds histogram
+goje_query_duration_seconds_bucket{db="orders",operation="INSERT",table="orders",le="0.01"} 0
+goje_query_duration_seconds_bucket{db="orders",operation="INSERT",table="orders",le="0.1"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="INSERT",table="orders",le="1"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="INSERT",table="orders",le="+Inf"} 1
+goje_query_duration_seconds_sum{db="orders",operation="INSERT",table="orders"} 0.05
+goje_query_duration_seconds_count{db="orders",operation="INSERT",table="orders"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="SELECT",table="orders",le="0.01"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="SELECT",table="orders",le="0.1"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="SELECT",table="orders",le="1"} 1
+goje_query_duration_seconds_bucket{db="orders",operation="SELECT",table="orders",le="+Inf"} 1
+goje_query_duration_seconds_sum{db="orders",operation="SELECT",table="orders"} 0.005
+goje_query_duration_seconds_count{db="orders",operation="SELECT",table="orders"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"goje_db_max_open_connections", "goje_query_duration_seconds"); err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(collector, "goje_db_open_connections", "goje_db_wait_count_total"); count != 2 {
+		t.Errorf("pool metrics = %d, want 2", count)
+	}
+}
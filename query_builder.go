@@ -25,10 +25,10 @@ type QueryInterface interface {
 }
 
 const (
-	Select string = "SELECT"
-	Insert string = "INSERT"
-	Update string = "UPDATE"
-	Delete string = "DELETE"
+	ActionSelect string = "SELECT"
+	ActionInsert string = "INSERT"
+	ActionUpdate string = "UPDATE"
+	ActionDelete string = "DELETE"
 
 	Left    string = "LEFT"
 	Right   string = "RIGHT"
@@ -39,39 +39,92 @@ const (
 
 // make a select ... FROM query
 func SelectQueryBuilder(Tablename string, Columns []string, Queries []QueryInterface) (string, []any, error) {
-	return ArgumentLessQueryBuilder(Select, Tablename, Columns, Queries)
+	return DialectSelectQueryBuilder(DefaultDialect, Tablename, Columns, Queries)
+}
+
+// DialectSelectQueryBuilder make a select ... FROM query in the dialect syntax
+func DialectSelectQueryBuilder(dialect Dialect, Tablename string, Columns []string, Queries []QueryInterface) (string, []any, error) {
+	return DialectArgumentLessQueryBuilder(dialect, ActionSelect, Tablename, Columns, Queries)
 }
 
 // ArgumentLessQueryBuilder (Select, Delete) query builder
 func ArgumentLessQueryBuilder(Action, Tablename string, Columns []string, Queries []QueryInterface) (string, []any, error) {
+	return DialectArgumentLessQueryBuilder(DefaultDialect, Action, Tablename, Columns, Queries)
+}
+
+// DialectArgumentLessQueryBuilder (Select, Delete) query builder in the dialect syntax
+func DialectArgumentLessQueryBuilder(dialect Dialect, Action, Tablename string, Columns []string, Queries []QueryInterface) (string, []any, error) {
+
+	query, args, err := argumentLessQuery(dialect, Action, Tablename, Columns, Queries)
+	return Rebind(dialect, query), args, err
+}
+
+// argumentLessQuery (Select, Delete) query in neutral syntax, args of CTEs, subquery columns and FROM come first
+func argumentLessQuery(dialect Dialect, Action, Tablename string, Columns []string, Queries []QueryInterface) (string, []any, error) {
 
-	if Action != Select && Action != Delete {
+	if Action != ActionSelect && Action != ActionDelete {
 		return "", nil, errors.New("this function dosen't support: " + Action)
 	}
 
 	query := Action
+	var args []any
 
 	Columns = columnsFilter(Columns)
-	if Action == Select {
+	Columns = Columns[:len(Columns):len(Columns)]
+	if Action == ActionSelect {
+		with, wargs := withClause(Queries)
+		query = with + query
+		args = append(args, wargs...)
+		for _, q := range Queries {
+			if q.GetType() == QueryTypeColumn {
+				Columns = append(Columns, q.GetQuery())
+				args = append(args, q.GetArgs()...)
+			}
+		}
 		query += " " + strings.Join(Columns, ",") + " "
+	} else if err := rejectWith(Action, Queries); err != nil {
+		return "", nil, err
 	}
 
-	query += " FROM " + qouteColumn(Tablename)
+	// the last derived table wins
+	var from QueryInterface
+	for _, q := range Queries {
+		if q.GetType() == QueryTypeFrom {
+			from = q
+		}
+	}
+	if from != nil {
+		query += " FROM " + from.GetQuery()
+		args = append(args, from.GetArgs()...)
+	} else {
+		query += " FROM " + qouteColumn(Tablename)
+	}
 
-	conditions, args, err := SQLConditionBuilder(Queries)
+	conditions, cargs, err := DialectSQLConditionBuilder(dialect, Queries)
 
-	return query + conditions, args, err
+	return query + conditions, append(args, cargs...), err
 }
 
 // SQLConditionBuilder [JOIN WHERE LIMIT OFFSET] ...builder
 func SQLConditionBuilder(Queries []QueryInterface) (string, []any, error) {
+	return DialectSQLConditionBuilder(DefaultDialect, Queries)
+}
+
+// DialectSQLConditionBuilder [JOIN WHERE LIMIT OFFSET] ...builder
+// the output is neutral (`?` binds) and should be passed to Rebind after it joined to the statement
+func DialectSQLConditionBuilder(dialect Dialect, Queries []QueryInterface) (string, []any, error) {
 	query := " "
 	var args []any
 	var where []string
+	for _, q := range Queries {
+		if err := queryErr(q); err != nil {
+			return "", nil, err
+		}
+	}
 	//Produce Joins
 	for _, q := range Queries {
 		if q.GetType() == QueryTypeJoin {
-			if strings.Count(q.GetQuery(), "?") != len(q.GetArgs()) {
+			if countBinds(q.GetQuery()) != len(q.GetArgs()) {
 				return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
 			}
 			query += q.GetQuery()
@@ -85,7 +138,7 @@ func SQLConditionBuilder(Queries []QueryInterface) (string, []any, error) {
 			q.GetType() == QueryTypeOR ||
 			q.GetType() == QueryTypeWhereIn ||
 			q.GetType() == QueryTypeWhereNotIn {
-			if strings.Count(q.GetQuery(), "?") != len(q.GetArgs()) {
+			if countBinds(q.GetQuery()) != len(q.GetArgs()) {
 				return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
 			}
 			where = append(where, "("+q.GetQuery()+")")
@@ -101,7 +154,7 @@ func SQLConditionBuilder(Queries []QueryInterface) (string, []any, error) {
 	var groupbys []string
 	for _, q := range Queries {
 		if q.GetType() == QueryTypeGroup {
-			if strings.Count(q.GetQuery(), "?") != len(q.GetArgs()) {
+			if countBinds(q.GetQuery()) != len(q.GetArgs()) {
 				return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
 			}
 			groupbys = append(groupbys, qouteColumn(q.GetQuery()))
@@ -117,7 +170,7 @@ func SQLConditionBuilder(Queries []QueryInterface) (string, []any, error) {
 	var havings []string
 	for _, q := range Queries {
 		if q.GetType() == QueryTypeHaving {
-			if strings.Count(q.GetQuery(), "?") != len(q.GetArgs()) {
+			if countBinds(q.GetQuery()) != len(q.GetArgs()) {
 				return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
 			}
 			havings = append(havings, qouteColumn(q.GetQuery()))
@@ -129,30 +182,84 @@ func SQLConditionBuilder(Queries []QueryInterface) (string, []any, error) {
 		query += " HAVING " + strings.Join(havings, " AND ")
 	}
 
-	//Add orders
+	//Add named windows
+	var windows []string
+	for _, q := range Queries {
+		if q.GetType() == QueryTypeWindow {
+			windows = append(windows, q.GetQuery())
+		}
+	}
+	if len(windows) > 0 {
+		query += " WINDOW " + strings.Join(windows, ",")
+	}
+
+	//Add orders, all of them in one clause
+	var orders []string
 	for _, q := range Queries {
 		if q.GetType() == QueryTypeOrder {
 
-			if strings.Count(q.GetQuery(), "?") != len(q.GetArgs()) {
+			if countBinds(q.GetQuery()) != len(q.GetArgs()) {
 				return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
 			}
 
-			query += " ORDER BY " + q.GetQuery()
+			orders = append(orders, q.GetQuery())
 			args = append(args, q.GetArgs()...)
 		}
 	}
+	if len(orders) > 0 {
+		query += " ORDER BY " + strings.Join(orders, ",")
+	}
 
-	//Add limitations
+	//Add limitations, the last limit and offset win
+	var limit, offset QueryInterface
 	for _, q := range Queries {
-		if q.GetType() == QueryTypeLimit || q.GetType() == QueryTypeOffset {
-			query += " " + q.GetQuery()
-			args = append(args, q.GetArgs()...)
+		switch q.GetType() {
+		case QueryTypeLimit:
+			limit = q
+		case QueryTypeOffset:
+			offset = q
+		}
+	}
+	if !builtinLimitOffset(limit, offset) {
+		// custom limit or offset queries are rendered as they are, in their order
+		for _, q := range Queries {
+			if q.GetType() == QueryTypeLimit || q.GetType() == QueryTypeOffset {
+				if countBinds(q.GetQuery()) != len(q.GetArgs()) {
+					return "", nil, errors.New(q.GetQuery() + "; args dosen't match with binds `?`")
+				}
+				query += " " + q.GetQuery()
+				args = append(args, q.GetArgs()...)
+			}
+		}
+	} else if limit != nil || offset != nil {
+		query += " " + dialect.LimitOffset(limit != nil, offset != nil)
+		if limit != nil {
+			args = append(args, limit.GetArgs()...)
+		}
+		if offset != nil {
+			args = append(args, offset.GetArgs()...)
 		}
 	}
 
 	return query, args, nil
 }
 
+// builtinLimitOffset reports whether limit and offset are Limit() and Offset() queries (or missing)
+// that are rendered by the dialect
+func builtinLimitOffset(limit, offset QueryInterface) bool {
+	if limit != nil {
+		if _, ok := limit.(QueryLimit); !ok {
+			return false
+		}
+	}
+	if offset != nil {
+		if _, ok := offset.(QueryOffset); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // filter multiple columns
 func columnsFilter(in []string) []string {
 	for i := range in {
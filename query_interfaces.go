@@ -140,6 +140,8 @@ func Having(query string, args ...any) QueryHaving {
 type QueryWhere struct {
 	query string
 	args  []any
+	// err of the subquery of Exists/NotExists
+	err error
 }
 
 func (q QueryWhere) GetType() string {
@@ -219,6 +221,9 @@ func (q QueryWhereIn) GetType() string {
 }
 
 func (q QueryWhereIn) GetQuery() string {
+	if sub, ok := subqueryOf(q.args); ok {
+		return q.column + " IN " + sub.GetQuery()
+	}
 	if len(q.args) == 0 {
 		return "1"
 	}
@@ -227,6 +232,9 @@ func (q QueryWhereIn) GetQuery() string {
 }
 
 func (q QueryWhereIn) GetArgs() []any {
+	if sub, ok := subqueryOf(q.args); ok {
+		return sub.GetArgs()
+	}
 	return q.args
 }
 
@@ -251,6 +259,9 @@ func (q QueryWhereNotIn) GetType() string {
 }
 
 func (q QueryWhereNotIn) GetQuery() string {
+	if sub, ok := subqueryOf(q.args); ok {
+		return q.column + " NOT IN " + sub.GetQuery()
+	}
 	if len(q.args) == 0 {
 		return "1"
 	}
@@ -259,6 +270,9 @@ func (q QueryWhereNotIn) GetQuery() string {
 }
 
 func (q QueryWhereNotIn) GetArgs() []any {
+	if sub, ok := subqueryOf(q.args); ok {
+		return sub.GetArgs()
+	}
 	return q.args
 }
 
@@ -278,6 +292,8 @@ type QueryJoin struct {
 	table    string
 	on       string
 	args     []any
+	// err of the derived table of JoinSub
+	err error
 }
 
 func (q QueryJoin) GetType() string {
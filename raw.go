@@ -1,28 +1,16 @@
 package goje
 
-import (
-	"log"
-	"strings"
-	"time"
-)
+import "strings"
 
 // RawDelete Deletes entries with standard query
-// This method dosen't support After,Before Triggers ...
+// This method dosen't support After,Before Triggers, use entity operations for hooks
 func (handler *Context) RawDelete(Tablename string, Queries []QueryInterface) (int64, error) {
-	query, args, err := ArgumentLessQueryBuilder(Delete, Tablename, nil, Queries)
+	query, args, err := DialectArgumentLessQueryBuilder(handler.GetDialect(), ActionDelete, Tablename, nil, Queries)
 	if err != nil {
 		return -1, err
 	}
 
-	start := time.Now()
-	// run query
-	res, err := handler.DB.ExecContext(handler.Ctx, query, args...)
-	// log slow queries
-	elapsed := time.Since(start)
-	if SlowQueryLogTimeout > 0 && elapsed > SlowQueryLogTimeout {
-		log.Printf("[SLOW QUERY] took=%s method=RawDelete(Tablename:%s) query=%s\n", elapsed, Tablename, query)
-	}
-
+	res, err := handler.exec("RawDelete", Tablename, query, args)
 	if err != nil {
 		return -1, err
 	}
@@ -31,33 +19,31 @@ func (handler *Context) RawDelete(Tablename string, Queries []QueryInterface) (i
 }
 
 // RawUpdate update entries by map
-// This method dosen't support After,Before Triggers ...
+// This method dosen't support After,Before Triggers, use entity operations for hooks
 func (handler *Context) RawUpdate(Tablename string, Cols map[string]any, Queries ...QueryInterface) (int64, error) {
 	if len(Cols) == 0 {
 		return -1, ErrNoColsSetForUpdate
 	}
-	query := Update + " " + Tablename + " SET "
+	if err := rejectWith(ActionUpdate, Queries); err != nil {
+		return -1, err
+	}
+	dialect := handler.GetDialect()
+	query := ActionUpdate + " " + qouteColumn(Tablename) + " SET "
 	var args []any
 	var items []string
 	for key, val := range Cols {
-		items = append(items, Tablename+"."+key+" = ?")
+		items = append(items, qouteColumn(key)+" = ?")
 		args = append(args, val)
 	}
 
-	conditions, cargs, err := SQLConditionBuilder(Queries)
+	conditions, cargs, err := DialectSQLConditionBuilder(dialect, Queries)
 	if err != nil {
 		return -1, err
 	}
 	args = append(args, cargs...)
-	start := time.Now()
-
-	query = query + strings.Join(items, ",") + conditions
-	res, err := handler.DB.ExecContext(handler.Ctx, query, args...)
-	// log slow queries
-	elapsed := time.Since(start)
-	if SlowQueryLogTimeout > 0 && elapsed > SlowQueryLogTimeout {
-		log.Printf("[SLOW QUERY] took=%s method=RawUpdate(Tablename:%s) query=%s\n", elapsed, Tablename, query)
-	}
+
+	query = Rebind(dialect, query+strings.Join(items, ",")+conditions)
+	res, err := handler.exec("RawUpdate", Tablename, query, args)
 	if err != nil {
 		return -1, err
 	}
@@ -66,13 +52,13 @@ func (handler *Context) RawUpdate(Tablename string, Cols map[string]any, Queries
 }
 
 // RawBulkInsert insert multiple entries by []map[column name]value
-// This method dosen't support After,Before Triggers ...
+// This method dosen't support After,Before Triggers, use entity operations for hooks
 func (handler *Context) RawBulkInsert(Tablename string, Rows []map[string]any) (int64, error) {
 	return RawBulkInsert(handler, false, Tablename, Rows)
 }
 
 // RawBulkInsertIgnore insert ignore errors multiple entries by []map[column name]value
-// This method dosen't support After,Before Triggers ...
+// This method dosen't support After,Before Triggers, use entity operations for hooks
 func (handler *Context) RawBulkInsertIgnore(Tablename string, Rows []map[string]any) (int64, error) {
 	return RawBulkInsert(handler, true, Tablename, Rows)
 }
@@ -83,12 +69,8 @@ func RawBulkInsert(handler *Context, Ignore bool, Tablename string, Rows []map[s
 		return -1, ErrNoRowsForInsert
 	}
 
-	strict := " INTO "
-	if Ignore {
-		strict = " IGNORE "
-	}
-
-	query := Insert + strict + Tablename
+	dialect := handler.GetDialect()
+	query := dialect.InsertVerb(Ignore) + " " + qouteColumn(Tablename)
 	var args []any
 	var columnNames []string
 
@@ -119,14 +101,9 @@ func RawBulkInsert(handler *Context, Ignore bool, Tablename string, Rows []map[s
 	values := strings.Repeat(eachRowArgs, len(Rows))
 	values = values[1:]
 
-	start := time.Now()
-	res, err := handler.DB.ExecContext(handler.Ctx, query+"("+strings.Join(columnNames, ",")+") VALUES "+values, args...)
-
-	elapsed := time.Since(start)
-	if SlowQueryLogTimeout > 0 && elapsed > SlowQueryLogTimeout {
-		log.Printf("[SLOW QUERY] took=%s method=RawBulkInsert(Tablename:%s) query=%s\n", elapsed, Tablename, query+"("+strings.Join(columnNames, ",")+") VALUES ...")
-	}
+	query += "(" + strings.Join(columnsFilter(columnNames), ",") + ") VALUES "
 
+	res, err := handler.exec("RawBulkInsert", Tablename, Rebind(dialect, query+values+dialect.InsertSuffix(Ignore)), args)
 	if err != nil {
 		return -1, err
 	}
@@ -0,0 +1,121 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DBNamer entities that live in a named database of the registry (generated by goje gen -db)
+type DBNamer interface {
+	GetDBName() string
+}
+
+var (
+	registryMu sync.RWMutex
+	// registry: [name]*sql.DB of RegisterDB
+	registry = map[string]*sql.DB{}
+)
+
+// RegisterDB connects to the database and registers it by name, DBConfig.Name is the name if it isn't set
+func RegisterDB(name string, conn *DBConfig) error {
+	c := *conn
+	if c.Name == "" {
+		c.Name = name
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDBAlreadyRegistered, name)
+	}
+
+	db, err := NewDBConnection(&c)
+	if err != nil {
+		return err
+	}
+	registry[name] = db
+	return nil
+}
+
+// RegisterDBConnection registers an opened connection (e.g. primary of a cluster) by name
+func RegisterDBConnection(name string, db *sql.DB) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDBAlreadyRegistered, name)
+	}
+	registry[name] = db
+	return nil
+}
+
+// GetDB returns the registered database by name
+func GetDB(name string) (*sql.DB, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	db, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDB, name)
+	}
+	return db, nil
+}
+
+// MakeHandlerNamed make a handler from the registered database
+func MakeHandlerNamed(ctx context.Context, name string) (*Context, error) {
+	db, err := GetDB(name)
+	if err != nil {
+		return nil, err
+	}
+	return MakeHandlerDB(ctx, db), nil
+}
+
+// MakeTxHandlerNamed make a transactional handler from the registered database
+func MakeTxHandlerNamed(ctx context.Context, name string, options *sql.TxOptions) (*Context, error) {
+	db, err := GetDB(name)
+	if err != nil {
+		return nil, err
+	}
+	return MakeTxHandlerDB(ctx, db, options)
+}
+
+// MakeEntityHandler make a handler from the database of the entity (DBNamer), the default database otherwise
+//
+//	handler, err := goje.MakeEntityHandler(ctx, &models.Invoices{})
+//	invoices, err := goje.Select[models.Invoices](handler, models.InvoicesTable)
+func MakeEntityHandler(ctx context.Context, entity any) (*Context, error) {
+	namer, ok := entity.(DBNamer)
+	if !ok || namer.GetDBName() == "" {
+		return MakeHandler(ctx), nil
+	}
+	return MakeHandlerNamed(ctx, namer.GetDBName())
+}
+
+// RegisteredDBs returns names of the registered databases
+func RegisteredDBs() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CloseAll closes and unregisters all registered databases
+func CloseAll() error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var errs []error
+	for name, db := range registry {
+		if err := CloseDB(db); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		delete(registry, name)
+	}
+	return errors.Join(errs...)
+}
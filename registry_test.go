@@ -0,0 +1,56 @@
+package goje
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testBillingEntity struct{ testUser }
+
+func (testBillingEntity) GetDBName() string { return "billing" }
+
+func TestRegistry(t *testing.T) {
+	defer CloseAll()
+
+	billing, fake := newFakeDB(t)
+	catalog, _ := newFakeDB(t)
+	if err := RegisterDBConnection("billing", billing); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDBConnection("catalog", catalog); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDBConnection("billing", catalog); !errors.Is(err, ErrDBAlreadyRegistered) {
+		t.Errorf("RegisterDBConnection() error = %v, want %v", err, ErrDBAlreadyRegistered)
+	}
+	if err := RegisterDB("catalog", &DBConfig{Driver: "mysql"}); !errors.Is(err, ErrDBAlreadyRegistered) {
+		t.Errorf("RegisterDB() error = %v, want %v", err, ErrDBAlreadyRegistered)
+	}
+	if got := RegisteredDBs(); !reflect.DeepEqual(got, []string{"billing", "catalog"}) {
+		t.Errorf("RegisteredDBs() = %v", got)
+	}
+
+	handler, err := MakeEntityHandler(context.Background(), &testBillingEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handler.Exec("DELETE FROM `invoices`"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Log()) != 1 {
+		t.Errorf("entity handler didn't run on its database: %v", fake.Log())
+	}
+
+	if _, err := MakeHandlerNamed(context.Background(), "crm"); !errors.Is(err, ErrUnknownDB) {
+		t.Errorf("MakeHandlerNamed() error = %v, want %v", err, ErrUnknownDB)
+	}
+
+	if err := CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(RegisteredDBs()) != 0 || billing.Ping() == nil {
+		t.Errorf("CloseAll() didn't close and unregister databases")
+	}
+}
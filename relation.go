@@ -0,0 +1,482 @@
+package goje
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RelationKind kind of the relation between two entities
+type RelationKind int
+
+const (
+	// BelongsTo the entity holds the foreign key: orders.user_id -> users.id
+	BelongsTo RelationKind = iota + 1
+	// HasOne the related entity holds the foreign key, single row
+	HasOne
+	// HasMany the related entity holds the foreign key: users.id <- orders.user_id
+	HasMany
+	// ManyToMany through a join table: posts.id <- post_tags.post_id, post_tags.tag_id -> tags.id
+	ManyToMany
+)
+
+// Relation declares a relation of an entity, Name is the struct field that Load fills
+// a relation field is a pointer (BelongsTo, HasOne) or a slice (HasMany, ManyToMany) of the related entity
+type Relation struct {
+	Name  string
+	Kind  RelationKind
+	Table string
+	// LocalKey column of this entity, ForeignKey column of the related entity that equals it
+	LocalKey   string
+	ForeignKey string
+
+	// ManyToMany join table, JoinLocalKey refers to LocalKey and JoinForeignKey refers to ForeignKey
+	JoinTable      string
+	JoinLocalKey   string
+	JoinForeignKey string
+}
+
+// RelationEntity entities that declare relations (generated entities implement it)
+type RelationEntity interface {
+	GetRelations() []Relation
+}
+
+// ContextSetter entities that are bound to the context they loaded by (generated entities implement it)
+type ContextSetter interface {
+	SetCtx(ctx *Context)
+}
+
+// ParentSetter entities that keep the entity loaded them (generated entities implement it)
+type ParentSetter interface {
+	SetParent(parent *Entity)
+}
+
+// Load fills relations of the entities, nested relations are separated by dot: Load(ctx, users, "Orders", "Orders.Items")
+// entities is an entity, a slice of entities or a pointer to slice, every relation level runs one WHERE IN query
+// (two for ManyToMany) instead of a query per entity.
+// A related row that is already an ancestor in its path (by table and primary key), or of the entities by GetParent,
+// returns ErrRecursiveLoad, self referencing relations (e.g. categories.parent_id) load as long as rows don't cycle
+func Load(ctx *Context, entities any, paths ...string) error {
+	if ctx == nil || ctx.DB == nil {
+		return ErrHandlerIsNil
+	}
+
+	parents, typ, err := collectEntities(entities)
+	if err != nil || len(parents) == 0 {
+		return err
+	}
+
+	// ancestors: rows of each entity and the entities that loaded it
+	ancestors := make([][]string, len(parents))
+	for i := range parents {
+		entity, ok := parents[i].Addr().Interface().(Entity)
+		if !ok {
+			return ErrNotAnEntity
+		}
+		if key := rowKey(entity.GetTableName(), parents[i]); key != "" {
+			ancestors[i] = append(ancestors[i], key)
+		}
+		for parent := entity.GetParent(); parent != nil && *parent != nil; parent = (*parent).GetParent() {
+			key := rowKey((*parent).GetTableName(), reflect.Indirect(reflect.ValueOf(*parent)))
+			if key == "" || contains(ancestors[i], key) {
+				break
+			}
+			ancestors[i] = append(ancestors[i], key)
+		}
+	}
+
+	return loadTree(ctx, parents, typ, relationTree(paths), ancestors)
+}
+
+// rowKey identifies the row by the table and its primary key, empty if it hasn't any
+func rowKey(table string, v reflect.Value) string {
+	info, err := getStructInfo(v.Type())
+	if err != nil {
+		return ""
+	}
+
+	key := ""
+	for _, field := range info.fields {
+		if !field.hasOption(TagPrimaryKey) {
+			continue
+		}
+		_, k, ok := relationKey(v, field.index)
+		if !ok {
+			return ""
+		}
+		key += ":" + k
+	}
+	if key == "" {
+		return ""
+	}
+	return table + key
+}
+
+// relationNode a level of relation paths
+type relationNode struct {
+	names    []string
+	children map[string]*relationNode
+}
+
+// relationTree makes the tree of dotted relation paths, keeps the order
+func relationTree(paths []string) *relationNode {
+	root := &relationNode{children: map[string]*relationNode{}}
+	for _, path := range paths {
+		node := root
+		for _, name := range strings.Split(path, ".") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			child, ok := node.children[name]
+			if !ok {
+				child = &relationNode{children: map[string]*relationNode{}}
+				node.children[name] = child
+				node.names = append(node.names, name)
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// loadTree loads relations of the node, ancestors are rows in the path of each parent
+func loadTree(ctx *Context, parents []reflect.Value, typ reflect.Type, node *relationNode, ancestors [][]string) error {
+	if len(node.names) == 0 {
+		return nil
+	}
+
+	relations := map[string]Relation{}
+	if r, ok := reflect.New(typ).Interface().(RelationEntity); ok {
+		for _, relation := range r.GetRelations() {
+			relations[relation.Name] = relation
+		}
+	}
+
+	for _, name := range node.names {
+		relation, ok := relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", ErrUnknownRelation, typ.Name(), name)
+		}
+		children, childType, matches, assign, err := loadRelation(ctx, parents, typ, relation)
+		if err != nil {
+			return err
+		}
+
+		// paths: [child]rows in the paths of the child, a child could be related to many parents
+		paths := map[uintptr][]string{}
+		for i, matched := range matches {
+			for _, child := range matched {
+				key := rowKey(relation.Table, child)
+				if key != "" && contains(ancestors[i], key) {
+					return fmt.Errorf("%w: %s.%s -> %s", ErrRecursiveLoad, typ.Name(), name, key)
+				}
+				paths[child.Addr().Pointer()] = append(paths[child.Addr().Pointer()], ancestors[i]...)
+			}
+		}
+
+		// nested relations are loaded before assignment, so value (non pointer) relation fields get them
+		if len(children) > 0 {
+			childAncestors := make([][]string, len(children))
+			for i, child := range children {
+				childAncestors[i] = paths[child.Addr().Pointer()]
+				if key := rowKey(relation.Table, child); key != "" {
+					childAncestors[i] = append(childAncestors[i], key)
+				}
+			}
+			err := loadTree(ctx, children, childType, node.children[name], childAncestors)
+			if err != nil {
+				return err
+			}
+		}
+
+		assign()
+	}
+
+	return nil
+}
+
+// loadRelation query related entities of the parents, matches are related children of each parent,
+// assign sets them to the relation fields
+func loadRelation(ctx *Context, parents []reflect.Value, typ reflect.Type, relation Relation) (children []reflect.Value, childType reflect.Type, matches [][]reflect.Value, assign func(), err error) {
+	field, ok := typ.FieldByName(relation.Name)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%w: %s.%s field is missing", ErrUnknownRelation, typ.Name(), relation.Name)
+	}
+
+	many := field.Type.Kind() == reflect.Slice
+	childType = field.Type
+	if many {
+		childType = childType.Elem()
+	}
+	if childType.Kind() == reflect.Pointer {
+		childType = childType.Elem()
+	}
+
+	parentInfo, err := getStructInfo(typ)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	childInfo, err := getStructInfo(childType)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	localField, ok := parentInfo.field(relation.LocalKey)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%w: %s", ErrUnknownColumn, relation.LocalKey)
+	}
+	foreignField, ok := childInfo.field(relation.ForeignKey)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%w: %s", ErrUnknownColumn, relation.ForeignKey)
+	}
+
+	// parentKeys: key of each parent
+	parentKeys := make([]string, len(parents))
+	keys, seen := []any{}, map[string]bool{}
+	for i, parent := range parents {
+		value, key, ok := relationKey(parent, localField.index)
+		if !ok {
+			continue
+		}
+		parentKeys[i] = key
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, value)
+		}
+	}
+
+	// links: [parent key]related keys, only ManyToMany has a link between parent and related keys
+	var links map[string][]string
+	childKeys := keys
+	if relation.Kind == ManyToMany && len(keys) > 0 {
+		links, childKeys, err = loadJoinTable(ctx, relation, keys)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	// related: [foreign key]children
+	related := map[string][]reflect.Value{}
+	if len(childKeys) > 0 {
+		children, err = selectValues(ctx, relation.Table, childInfo, childType, WhereIn(qouteColumn(relation.ForeignKey), childKeys...))
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		for _, child := range children {
+			if _, key, ok := relationKey(child, foreignField.index); ok {
+				related[key] = append(related[key], child)
+			}
+		}
+	}
+
+	matches = make([][]reflect.Value, len(parents))
+	for i := range parents {
+		if links != nil {
+			for _, key := range links[parentKeys[i]] {
+				matches[i] = append(matches[i], related[key]...)
+			}
+		} else if parentKeys[i] != "" {
+			matches[i] = related[parentKeys[i]]
+		}
+	}
+
+	assign = func() {
+		for i, parent := range parents {
+			setRelation(ctx, parent, field, many, matches[i])
+		}
+	}
+
+	return children, childType, matches, assign, nil
+}
+
+// loadJoinTable returns [parent key]related keys and unique related keys of a ManyToMany join table
+func loadJoinTable(ctx *Context, relation Relation, keys []any) (map[string][]string, []any, error) {
+	query, args, err := DialectSelectQueryBuilder(ctx.GetDialect(), relation.JoinTable,
+		[]string{relation.JoinLocalKey, relation.JoinForeignKey},
+		[]QueryInterface{WhereIn(qouteColumn(relation.JoinLocalKey), keys...)},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := ctx.reader().query("Load", relation.JoinTable, query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	links := map[string][]string{}
+	var childKeys []any
+	seen := map[string]bool{}
+	for rows.Next() {
+		var local, foreign any
+		if err := rows.Scan(&local, &foreign); err != nil {
+			return nil, nil, err
+		}
+		localKey, ok := normalizeKey(local)
+		if !ok {
+			continue
+		}
+		foreignKey, ok := normalizeKey(foreign)
+		if !ok {
+			continue
+		}
+		links[localKey] = append(links[localKey], foreignKey)
+		if !seen[foreignKey] {
+			seen[foreignKey] = true
+			childKeys = append(childKeys, foreign)
+		}
+	}
+
+	return links, childKeys, rows.Err()
+}
+
+// selectValues query rows of the struct type, returns addressable struct values
+func selectValues(ctx *Context, Tablename string, info *structInfo, typ reflect.Type, Queries ...QueryInterface) ([]reflect.Value, error) {
+	rows, scanner, err := queryInfo(ctx, Tablename, info, Queries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []reflect.Value
+	for rows.Next() {
+		dest := reflect.New(typ).Elem()
+		if err := scanner.Scan(rows, dest); err != nil {
+			return nil, err
+		}
+		bindContext(ctx, dest)
+		out = append(out, dest)
+	}
+	return out, rows.Err()
+}
+
+// setRelation sets matched related entities to the relation field of the parent
+func setRelation(ctx *Context, parent reflect.Value, field reflect.StructField, many bool, matched []reflect.Value) {
+	target := fieldByIndex(parent, field.Index)
+
+	if e, ok := parent.Addr().Interface().(Entity); ok {
+		for _, child := range matched {
+			if s, ok := child.Addr().Interface().(ParentSetter); ok {
+				s.SetParent(&e)
+			}
+		}
+	}
+
+	if !many {
+		target.Set(reflect.Zero(target.Type()))
+		if len(matched) == 0 {
+			return
+		}
+		if target.Kind() == reflect.Pointer {
+			target.Set(matched[0].Addr())
+		} else {
+			target.Set(matched[0])
+		}
+		return
+	}
+
+	slice := reflect.MakeSlice(target.Type(), 0, len(matched))
+	for _, child := range matched {
+		if target.Type().Elem().Kind() == reflect.Pointer {
+			slice = reflect.Append(slice, child.Addr())
+		} else {
+			slice = reflect.Append(slice, child)
+		}
+	}
+	target.Set(slice)
+}
+
+// collectEntities returns addressable struct values of an entity, a slice or a pointer to slice
+func collectEntities(entities any) ([]reflect.Value, reflect.Type, error) {
+	v := reflect.ValueOf(entities)
+	if !v.IsValid() {
+		return nil, nil, ErrNotAnEntity
+	}
+	if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice {
+		if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return nil, nil, ErrEntityIsntPointer
+		}
+		return []reflect.Value{v.Elem()}, v.Elem().Type(), nil
+	}
+
+	out := make([]reflect.Value, 0, v.Len())
+	elem := v.Type().Elem()
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Pointer {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		out = append(out, item)
+	}
+
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, ErrNotAStruct
+	}
+	return out, elem, nil
+}
+
+// bindContext set the context of a ContextSetter struct value
+func bindContext(ctx *Context, v reflect.Value) {
+	if s, ok := v.Addr().Interface().(ContextSetter); ok {
+		s.SetCtx(ctx)
+	}
+}
+
+// relationKey returns the value of a key field and its comparable form
+func relationKey(v reflect.Value, index []int) (any, string, bool) {
+	field, ok := fieldValue(v, index)
+	if !ok {
+		return nil, "", false
+	}
+	value := field.Interface()
+	key, ok := normalizeKey(value)
+	return value, key, ok
+}
+
+// normalizeKey makes a comparable form of a key value, so int32, int64 and []byte from different drivers match
+func normalizeKey(value any) (string, bool) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", false
+		}
+		value = v
+	}
+
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", false
+	}
+
+	if b, ok := v.Interface().([]byte); ok {
+		return string(b), true
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+func contains(list []string, item string) bool {
+	for _, l := range list {
+		if l == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package goje
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testRelUser struct {
+	parent *Entity
+	ID     int64        `db:"id,pk"`
+	Name   string       `db:"name"`
+	Orders []*testOrder `db:"-"`
+}
+
+func (e *testRelUser) GetTableName() string     { return "users" }
+func (e *testRelUser) GetColumns() []string     { return []string{"id", "name"} }
+func (e *testRelUser) GetCtx() *Context         { return nil }
+func (e *testRelUser) GetParent() *Entity       { return e.parent }
+func (e *testRelUser) SetParent(parent *Entity) { e.parent = parent }
+func (e *testRelUser) GetRelations() []Relation {
+	return []Relation{
+		{Name: "Orders", Kind: HasMany, Table: "orders", LocalKey: "id", ForeignKey: "user_id"},
+	}
+}
+
+type testOrder struct {
+	parent *Entity
+	ID     int64        `db:"id,pk"`
+	UserID int32        `db:"user_id"`
+	User   *testRelUser `db:"-"`
+	Items  []testItem   `db:"-"`
+	Tags   []*testTag   `db:"-"`
+}
+
+func (e *testOrder) GetTableName() string     { return "orders" }
+func (e *testOrder) GetColumns() []string     { return []string{"id", "user_id"} }
+func (e *testOrder) GetCtx() *Context         { return nil }
+func (e *testOrder) GetParent() *Entity       { return e.parent }
+func (e *testOrder) SetParent(parent *Entity) { e.parent = parent }
+func (e *testOrder) GetRelations() []Relation {
+	return []Relation{
+		{Name: "User", Kind: BelongsTo, Table: "users", LocalKey: "user_id", ForeignKey: "id"},
+		{Name: "Items", Kind: HasMany, Table: "order_items", LocalKey: "id", ForeignKey: "order_id"},
+		{Name: "Tags", Kind: ManyToMany, Table: "tags", LocalKey: "id", ForeignKey: "id", JoinTable: "order_tags", JoinLocalKey: "order_id", JoinForeignKey: "tag_id"},
+	}
+}
+
+type testItem struct {
+	ID      int64  `db:"id,pk"`
+	OrderID int64  `db:"order_id"`
+	SKU     string `db:"sku"`
+}
+
+type testTag struct {
+	ID   int64  `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func testRelationDB(t *testing.T) (*Context, *fakeDB) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "FROM `users`"):
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "john"}}, nil
+		case strings.Contains(query, "FROM `orders`"):
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(11), int64(1)}, {int64(12), int64(2)}}, nil
+		case strings.Contains(query, "FROM `order_items`"):
+			return []string{"id", "order_id", "sku"}, [][]driver.Value{{int64(100), int64(10), "a"}, {int64(101), int64(10), "b"}, {int64(102), int64(12), "c"}}, nil
+		case strings.Contains(query, "FROM `order_tags`"):
+			return []string{"order_id", "tag_id"}, [][]driver.Value{{int64(10), int64(7)}, {int64(11), int64(7)}, {int64(11), []byte("8")}}, nil
+		case strings.Contains(query, "FROM `tags`"):
+			return []string{"id", "name"}, [][]driver.Value{{int64(7), "go"}, {int64(8), "sql"}}, nil
+		}
+		return nil, nil, errors.New("unexpected query " + query)
+	}
+	return MakeHandlerDB(context.Background(), db), fake
+}
+
+func TestLoad(t *testing.T) {
+	ctx, fake := testRelationDB(t)
+
+	users := []*testRelUser{{ID: 1}, {ID: 2}}
+	if err := Load(ctx, users, "Orders", "Orders.Items", "Orders.Tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	if log := fake.Log(); len(log) != 4 {
+		t.Errorf("Load() queries = %d, want 4 batched queries: %v", len(log), log)
+	}
+	if got := fake.Log()[0]; got != "SELECT `id`,`user_id`  FROM `orders`  WHERE (`user_id` IN(?,?))" {
+		t.Errorf("Load() orders query = %v", got)
+	}
+
+	if len(users[0].Orders) != 2 || len(users[1].Orders) != 1 {
+		t.Fatalf("Load() orders = %d, %d", len(users[0].Orders), len(users[1].Orders))
+	}
+	order := users[0].Orders[0]
+	if len(order.Items) != 2 || order.Items[1].SKU != "b" {
+		t.Errorf("Load() items = %+v", order.Items)
+	}
+	if len(order.Tags) != 1 || order.Tags[0].Name != "go" || len(users[0].Orders[1].Tags) != 2 {
+		t.Errorf("Load() tags = %+v, %+v", order.Tags, users[0].Orders[1].Tags)
+	}
+	if order.GetParent() == nil || (*order.GetParent()).(*testRelUser) != users[0] {
+		t.Errorf("Load() parent of order isn't set")
+	}
+}
+
+func TestLoadRecursive(t *testing.T) {
+	ctx, _ := testRelationDB(t)
+
+	users := []*testRelUser{{ID: 1}}
+	if err := Load(ctx, users, "Orders.User"); !errors.Is(err, ErrRecursiveLoad) {
+		t.Errorf("Load() error = %v, want %v", err, ErrRecursiveLoad)
+	}
+
+	if err := Load(ctx, users, "Orders"); err != nil {
+		t.Fatal(err)
+	}
+	// orders are loaded by users, loading their user cycles back
+	if err := Load(ctx, users[0].Orders, "User"); !errors.Is(err, ErrRecursiveLoad) {
+		t.Errorf("Load() error = %v, want %v", err, ErrRecursiveLoad)
+	}
+
+	orders := []testOrder{{ID: 10, UserID: 1}}
+	if err := Load(ctx, orders, "User"); err != nil {
+		t.Fatal(err)
+	}
+	if orders[0].User == nil || orders[0].User.Name != "john" {
+		t.Errorf("Load() user = %+v", orders[0].User)
+	}
+
+	if err := Load(ctx, orders, "Unknown"); !errors.Is(err, ErrUnknownRelation) {
+		t.Errorf("Load() error = %v, want %v", err, ErrUnknownRelation)
+	}
+}
+
+type testCategory struct {
+	ID       int64         `db:"id,pk"`
+	ParentID int64         `db:"parent_id"`
+	Parent   *testCategory `db:"-"`
+}
+
+func (e *testCategory) GetTableName() string { return "categories" }
+func (e *testCategory) GetColumns() []string { return []string{"id", "parent_id"} }
+func (e *testCategory) GetCtx() *Context     { return nil }
+func (e *testCategory) GetParent() *Entity   { return nil }
+func (e *testCategory) GetRelations() []Relation {
+	return []Relation{
+		{Name: "Parent", Kind: BelongsTo, Table: "categories", LocalKey: "parent_id", ForeignKey: "id"},
+	}
+}
+
+func TestLoadSelfReferencing(t *testing.T) {
+	db, fake := newFakeDB(t)
+	// 1 <- 2 <- 3, 4 <-> 5 cycles
+	parents := map[int64]int64{1: 0, 2: 1, 3: 2, 4: 5, 5: 4}
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		var rows [][]driver.Value
+		for _, arg := range args {
+			id := arg.(int64)
+			if parent, ok := parents[id]; ok {
+				rows = append(rows, []driver.Value{id, parent})
+			}
+		}
+		return []string{"id", "parent_id"}, rows, nil
+	}
+	ctx := MakeHandlerDB(context.Background(), db)
+
+	categories := []*testCategory{{ID: 3, ParentID: 2}, {ID: 2, ParentID: 1}}
+	if err := Load(ctx, categories, "Parent.Parent"); err != nil {
+		t.Fatal(err)
+	}
+	if categories[0].Parent == nil || categories[0].Parent.Parent == nil || categories[0].Parent.Parent.ID != 1 {
+		t.Errorf("Load() parents of 3 = %+v", categories[0].Parent)
+	}
+
+	cycle := []*testCategory{{ID: 4, ParentID: 5}}
+	if err := Load(ctx, cycle, "Parent"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(ctx, cycle, "Parent.Parent"); !errors.Is(err, ErrRecursiveLoad) {
+		t.Errorf("Load() error = %v, want %v", err, ErrRecursiveLoad)
+	}
+}
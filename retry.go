@@ -0,0 +1,131 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers of a transaction that could succeed by running again
+const (
+	MySQLErrLockWaitTimeout uint16 = 1205
+	MySQLErrDeadlock        uint16 = 1213
+)
+
+// RetryPolicy of WithRetryTx, zero fields fall back to DefaultRetryPolicy
+type RetryPolicy struct {
+	// MaxAttempts runs of the closure, including the first one
+	MaxAttempts int
+	// BaseDelay before the second attempt, it doubles on each attempt up to MaxDelay, with jitter
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Retryable classifies errors that restart the transaction
+	Retryable func(err error) bool
+	// OnRetry is called before waiting for the next attempt, attempt is the failed one,
+	// retries are logged at warn level by the logger of the database if it's nil
+	OnRetry func(attempt int, err error)
+}
+
+// DefaultRetryPolicy retries deadlocks and lock wait timeouts 3 times
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+	Retryable:   IsRetryableError,
+}
+
+// IsRetryableError check the error is a deadlock or a lock wait timeout,
+// MySQL 1213/1205 and SQLSTATE 40001/40P01 of drivers that expose SQLState() (pgx)
+func IsRetryableError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == MySQLErrDeadlock || mysqlErr.Number == MySQLErrLockWaitTimeout
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		return state == "40001" || state == "40P01"
+	}
+	return false
+}
+
+// WithRetryTx runs fn in a transaction of the default database like WithTx,
+// a retryable error restarts the whole closure on a fresh transaction, tx.Attempt() is the current attempt.
+// fn should not have side effects out of the transaction, it may run more than once
+func WithRetryTx(ctx context.Context, policy *RetryPolicy, options *sql.TxOptions, fn TxFunc) error {
+	return WithRetryTxDB(ctx, DefatultDB, policy, options, fn)
+}
+
+// WithRetryTxDB runs fn in a transaction of the database connection, same as WithRetryTx
+func WithRetryTxDB(ctx context.Context, db *sql.DB, policy *RetryPolicy, options *sql.TxOptions, fn TxFunc) error {
+	p := policy.withDefaults()
+	handler := MakeHandlerDB(ctx, db)
+
+	for attempt := 1; ; attempt++ {
+		err := handler.WithTx(options, func(tx *Context) error {
+			tx.attempt = attempt
+			return fn(tx)
+		})
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return err
+		}
+
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err)
+		} else {
+			handler.GetLogger().LogAttrs(ctx, slog.LevelWarn, "transaction retry",
+				slog.Int("attempt", attempt), slog.String("error", err.Error()))
+		}
+
+		timer := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// withDefaults fills zero fields by DefaultRetryPolicy
+func (p *RetryPolicy) withDefaults() RetryPolicy {
+	out := DefaultRetryPolicy
+	if p == nil {
+		return out
+	}
+	if p.MaxAttempts > 0 {
+		out.MaxAttempts = p.MaxAttempts
+	}
+	if p.BaseDelay > 0 {
+		out.BaseDelay = p.BaseDelay
+	}
+	if p.MaxDelay > 0 {
+		out.MaxDelay = p.MaxDelay
+	}
+	if p.Retryable != nil {
+		out.Retryable = p.Retryable
+	}
+	if p.OnRetry != nil {
+		out.OnRetry = p.OnRetry
+	}
+	return out
+}
+
+// delay after the failed attempt: exponential backoff with equal jitter, half of it is random
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
+}
@@ -0,0 +1,129 @@
+package goje
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "pq: " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, true},
+		{fmt.Errorf("update: %w", &mysql.MySQLError{Number: 1205}), true},
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, false},
+		{sqlStateErr("40P01"), true},
+		{sqlStateErr("23505"), false},
+		{errors.New("deadlock"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableError(tt.err); got != tt.want {
+			t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWithRetryTx(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: MySQLErrDeadlock, Message: "Deadlock found"}
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "first attempt", wantAttempts: 1},
+		{name: "retried", failures: 2, err: deadlock, wantAttempts: 3},
+		{name: "exhausted", failures: 5, err: deadlock, wantAttempts: 3, wantErr: deadlock},
+		{name: "not retryable", failures: 5, err: ErrNoRowsForInsert, wantAttempts: 1, wantErr: ErrNoRowsForInsert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+				if tt.failures > 0 {
+					tt.failures--
+					return nil, tt.err
+				}
+				return driver.RowsAffected(1), nil
+			}
+
+			var attempts, retries []int
+			policy := &RetryPolicy{
+				BaseDelay: time.Microsecond,
+				OnRetry:   func(attempt int, err error) { retries = append(retries, attempt) },
+			}
+			err := WithRetryTxDB(context.Background(), db, policy, nil, func(tx *Context) error {
+				attempts = append(attempts, tx.Attempt())
+				_, err := tx.RawUpdate("posts", map[string]any{"title": "x"}, Where("id = ?", 1))
+				return err
+			})
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("WithRetryTxDB() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(attempts) != tt.wantAttempts || attempts[len(attempts)-1] != tt.wantAttempts {
+				t.Errorf("WithRetryTxDB() attempts = %v, want %d", attempts, tt.wantAttempts)
+			}
+			if len(retries) != tt.wantAttempts-1 {
+				t.Errorf("WithRetryTxDB() OnRetry calls = %v", retries)
+			}
+		})
+	}
+}
+
+func TestRetryLog(t *testing.T) {
+	db, fake := newFakeDB(t)
+	var buf bytes.Buffer
+	ConfigureDBLog(db, LogConfig{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	failures := 1
+	fake.exec = func(query string, args []driver.Value) (driver.Result, error) {
+		if failures > 0 {
+			failures--
+			return nil, &mysql.MySQLError{Number: MySQLErrDeadlock, Message: "Deadlock found"}
+		}
+		return driver.RowsAffected(1), nil
+	}
+
+	err := WithRetryTxDB(context.Background(), db, &RetryPolicy{BaseDelay: time.Microsecond}, nil, func(tx *Context) error {
+		_, err := tx.Exec("UPDATE `posts` SET `title` = ?", "x")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `level=WARN msg="transaction retry" attempt=1 error="Error 1213: Deadlock found"`) {
+		t.Errorf("retry log = %s", out)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 10, 2: 20, 3: 40, 4: 50, 60: 50} {
+		max *= time.Millisecond
+		for range 20 {
+			if d := p.delay(attempt); d < max/2 || d > max {
+				t.Fatalf("delay(%d) = %s, want in [%s, %s]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package goje
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField a struct field that mapped to a column by `db` tag
+// tag format: `db:"column_name,option1,option2"`
+type structField struct {
+	column  string
+	index   []int
+	options []string
+}
+
+// hasOption check existence of a tag option
+func (f structField) hasOption(option string) bool {
+	for _, o := range f.options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// structInfo db mapping of a struct type
+type structInfo struct {
+	fields   []structField
+	byColumn map[string]int
+}
+
+// columns returns column names in the declared order
+func (s *structInfo) columns() []string {
+	out := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		out[i] = f.column
+	}
+	return out
+}
+
+// field find the field of a column, case insensitive as fallback
+func (s *structInfo) field(column string) (structField, bool) {
+	if i, ok := s.byColumn[column]; ok {
+		return s.fields[i], true
+	}
+	if i, ok := s.byColumn[strings.ToLower(column)]; ok {
+		return s.fields[i], true
+	}
+	return structField{}, false
+}
+
+// structInfoCache: [reflect.Type]*structInfo
+var structInfoCache sync.Map
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// getStructInfo returns db mapping of a struct or pointer to struct type
+func getStructInfo(t reflect.Type) (*structInfo, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, ErrNotAStruct
+	}
+
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+
+	info := &structInfo{byColumn: map[string]int{}}
+	collectFields(info, t, nil)
+	if len(info.fields) == 0 {
+		return nil, ErrNoDBFields
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo), nil
+}
+
+// collectFields add tagged fields of t, fields of embedded structs come after own fields
+// so a shallower field wins when two fields have the same column
+func collectFields(info *structInfo, t reflect.Type, parent []int) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" && isEmbeddable(field.Type) {
+			embedded = append(embedded, field)
+			continue
+		}
+
+		if !hasTag || tag == "" || !field.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		column := strings.TrimSpace(parts[0])
+		if column == "" {
+			continue
+		}
+		if _, exists := info.byColumn[column]; exists {
+			continue
+		}
+
+		var options []string
+		for _, o := range parts[1:] {
+			if o = strings.TrimSpace(o); o != "" {
+				options = append(options, o)
+			}
+		}
+
+		info.byColumn[column] = len(info.fields)
+		info.fields = append(info.fields, structField{
+			column:  column,
+			index:   append(append([]int{}, parent...), i),
+			options: options,
+		})
+	}
+
+	for _, field := range embedded {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		collectFields(info, t, append(append([]int{}, parent...), field.Index...))
+	}
+}
+
+// isEmbeddable embedded struct (or pointer to struct) that should be flattened
+func isEmbeddable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// fieldByIndex returns the field of v by index, allocates nil embedded pointers
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldValue returns the field of v by index, false when an embedded pointer is nil
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// rowScanner scans rows into structs by result column names
+type rowScanner struct {
+	info    *structInfo
+	columns []string
+}
+
+func newRowScanner(info *structInfo, rows *Rows) (*rowScanner, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return &rowScanner{info: info, columns: columns}, nil
+}
+
+// Scan current row into dest, dest should be an addressable struct value
+// unknown columns are discarded
+func (s *rowScanner) Scan(rows *Rows, dest reflect.Value) error {
+	targets := make([]any, len(s.columns))
+	for i, column := range s.columns {
+		field, ok := s.info.field(column)
+		if !ok {
+			targets[i] = new(any)
+			continue
+		}
+		targets[i] = fieldByIndex(dest, field.index).Addr().Interface()
+	}
+	return rows.Scan(targets...)
+}
+
+// newOf make a new T, T could be a struct or a pointer to struct
+// returns the T and its addressable struct value
+func newOf[T any]() (*T, reflect.Value) {
+	item := new(T)
+	v := reflect.ValueOf(item).Elem()
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	return item, v
+}
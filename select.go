@@ -0,0 +1,127 @@
+package goje
+
+import (
+	"database/sql"
+	"iter"
+	"reflect"
+)
+
+// Select query the table and scan rows into T by `db` struct tags
+// T should be a struct or a pointer to struct, selected columns are the tagged fields
+// embedded structs are flatten, use pointer or sql.Null* fields for nullable columns
+// items that implement ContextSetter are bound to the context
+func Select[T any](ctx *Context, Tablename string, Queries ...QueryInterface) ([]T, error) {
+	rows, scanner, err := queryStructs[T](ctx, Tablename, Queries)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows[T](ctx, rows, scanner)
+}
+
+// scanRows scan all rows into T and close them
+func scanRows[T any](ctx *Context, rows *Rows, scanner *rowScanner) ([]T, error) {
+	defer rows.Close()
+
+	var out []T
+	for rows.Next() {
+		item, dest := newOf[T]()
+		if err := scanner.Scan(rows, dest); err != nil {
+			return nil, err
+		}
+		bindContext(ctx, dest)
+		out = append(out, *item)
+	}
+
+	return out, rows.Err()
+}
+
+// Get query the first row of the table and scan it into T
+// returns sql.ErrNoRows if there isn't any row
+func Get[T any](ctx *Context, Tablename string, Queries ...QueryInterface) (T, error) {
+	var zero T
+	items, err := Select[T](ctx, Tablename, append(Queries[:len(Queries):len(Queries)], Limit(1))...)
+	if err != nil {
+		return zero, err
+	}
+	if len(items) == 0 {
+		return zero, sql.ErrNoRows
+	}
+	return items[0], nil
+}
+
+// Iterate query the table and yields rows one by one scanned into T, for large result sets
+// rows are closed when the loop ends or breaks, a cancelled ctx.Ctx stops the iteration with its error
+//
+//	for user, err := range goje.Iterate[User](handler, "users") {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Iterate[T any](ctx *Context, Tablename string, Queries ...QueryInterface) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, scanner, err := queryStructs[T](ctx, Tablename, Queries)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err := ctx.Ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			item, dest := newOf[T]()
+			if err := scanner.Scan(rows, dest); err != nil {
+				yield(zero, err)
+				return
+			}
+			bindContext(ctx, dest)
+			if !yield(*item, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// queryStructs run select query of T tagged columns
+func queryStructs[T any](ctx *Context, Tablename string, Queries []QueryInterface) (*Rows, *rowScanner, error) {
+	if ctx == nil || ctx.DB == nil {
+		return nil, nil, ErrHandlerIsNil
+	}
+
+	info, err := getStructInfo(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return queryInfo(ctx, Tablename, info, Queries)
+}
+
+// queryInfo run select query of the struct mapping columns
+func queryInfo(ctx *Context, Tablename string, info *structInfo, Queries []QueryInterface) (*Rows, *rowScanner, error) {
+	query, args, err := DialectSelectQueryBuilder(ctx.GetDialect(), Tablename, info.columns(), Queries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := ctx.reader().query("Select", Tablename, query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scanner, err := newRowScanner(info, rows)
+	if err != nil {
+		rows.Close()
+		return nil, nil, err
+	}
+
+	return rows, scanner, nil
+}
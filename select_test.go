@@ -0,0 +1,142 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testTimestamps struct {
+	CreatedAt string `db:"created_at"`
+}
+
+type testUser struct {
+	testTimestamps
+	ID       int            `db:"id,pk,autoincrement"`
+	Name     string         `db:"name"`
+	Nickname *string        `db:"nickname"`
+	Email    sql.NullString `db:"email"`
+	Ignored  string         `db:"-"`
+	NoTag    string
+}
+
+func TestGetStructInfo(t *testing.T) {
+	info, err := getStructInfo(reflect.TypeFor[*testUser]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"id", "name", "nickname", "email", "created_at"}
+	got := info.columns()
+	if len(got) != len(want) {
+		t.Fatalf("columns() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("columns() = %v, want %v", got, want)
+		}
+	}
+
+	id, ok := info.field("id")
+	if !ok || !id.hasOption("pk") || !id.hasOption("autoincrement") {
+		t.Errorf("field(id) = %+v, want pk and autoincrement options", id)
+	}
+
+	if _, err := getStructInfo(reflect.TypeFor[int]()); !errors.Is(err, ErrNotAStruct) {
+		t.Errorf("getStructInfo(int) error = %v, want %v", err, ErrNotAStruct)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name", "nickname", "email", "created_at", "extra"}, [][]driver.Value{
+			{int64(1), "john", nil, "john@example.com", "2024-01-01", "x"},
+			{int64(2), "jane", "jj", nil, "2024-01-02", "y"},
+		}, nil
+	}
+
+	ctx := MakeHandlerDB(context.Background(), db)
+	users, err := Select[testUser](ctx, "users", Gt("id", 0), Order("id ASC"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQuery := "SELECT `id`,`name`,`nickname`,`email`,`created_at`  FROM `users`  WHERE (`id` > ?) ORDER BY id ASC"
+	if log := fake.Log(); len(log) != 1 || log[0] != wantQuery {
+		t.Errorf("Select() query = %v, want %v", log, wantQuery)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("Select() len = %d, want 2", len(users))
+	}
+	if users[0].ID != 1 || users[0].Name != "john" || users[0].Nickname != nil || users[0].Email.String != "john@example.com" || users[0].CreatedAt != "2024-01-01" {
+		t.Errorf("Select() [0] = %+v", users[0])
+	}
+	if users[1].Nickname == nil || *users[1].Nickname != "jj" || users[1].Email.Valid {
+		t.Errorf("Select() [1] = %+v", users[1])
+	}
+
+	ptrs, err := Select[*testUser](ctx, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 2 || ptrs[1].Name != "jane" {
+		t.Errorf("Select[*testUser]() = %+v", ptrs)
+	}
+}
+
+func TestGet(t *testing.T) {
+	db, _ := newFakeDB(t)
+	ctx := MakeHandlerDB(context.Background(), db)
+
+	if _, err := Get[testUser](ctx, "users", Eq("id", 1)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	// Get keeps queries of the caller
+	qs := []QueryInterface{Eq("id", 1), Eq("name", "john")}
+	if _, err := Get[testUser](ctx, "users", qs[:1]...); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal(err)
+	}
+	if qs[1].GetType() != QueryTypeWhere {
+		t.Errorf("Get() changed queries of the caller: %T", qs[1])
+	}
+}
+
+func TestIterate(t *testing.T) {
+	db, fake := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name"}, [][]driver.Value{
+			{int64(1), "john"},
+			{int64(2), "jane"},
+			{int64(3), "bob"},
+		}, nil
+	}
+
+	ctx := MakeHandlerDB(context.Background(), db)
+
+	var names []string
+	for user, err := range Iterate[testUser](ctx, "users") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, user.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	if len(names) != 2 || names[0] != "john" || names[1] != "jane" {
+		t.Errorf("Iterate() names = %v", names)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range Iterate[testUser](MakeHandlerDB(cancelled, db), "users") {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Iterate() error = %v, want %v", err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package goje
+
+import "errors"
+
+const (
+	QueryTypeFrom   = "from"
+	QueryTypeColumn = "column"
+)
+
+/**
+	Subquery
+**/
+
+// Subquery a SELECT that's used in another query, it's rendered in neutral syntax
+// and its args are merged into the outer query by position
+//
+//	goje.WhereIn("user_id", goje.Sub("orders", []string{"user_id"}, goje.Where("total > ?", 100)))
+//	goje.Exists(goje.Sub("orders", []string{"*"}, goje.Where("orders.user_id = users.id")))
+type Subquery struct {
+	query string
+	args  []any
+	err   error
+}
+
+// Sub make a subquery by SelectQueryBuilder inputs in the DefaultDialect
+func Sub(Tablename string, Columns []string, Queries ...QueryInterface) *Subquery {
+	return DialectSub(DefaultDialect, Tablename, Columns, Queries...)
+}
+
+// DialectSub make a subquery by SelectQueryBuilder inputs in the dialect
+func DialectSub(dialect Dialect, Tablename string, Columns []string, Queries ...QueryInterface) *Subquery {
+	query, args, err := argumentLessQuery(dialect, ActionSelect, Tablename, Columns, Queries)
+	return &Subquery{query: query, args: args, err: err}
+}
+
+// GetQuery returns the parenthesized SELECT
+func (s *Subquery) GetQuery() string {
+	return "(" + s.query + ")"
+}
+
+func (s *Subquery) GetArgs() []any {
+	return s.args
+}
+
+// Err returns error of building the subquery
+func (s *Subquery) Err() error {
+	return s.err
+}
+
+// as returns the subquery with an alias
+func (s *Subquery) as(alias string) string {
+	return s.GetQuery() + " AS " + qouteColumn(alias)
+}
+
+// subqueryOf returns the subquery if it's the only arg of WhereIn/WhereNotIn
+func subqueryOf(args []any) (*Subquery, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	sub, ok := args[0].(*Subquery)
+	return sub, ok && sub != nil
+}
+
+// queryErr returns error of subqueries of the query part
+func queryErr(q QueryInterface) error {
+	if e, ok := q.(interface{ subqueryErr() error }); ok {
+		return e.subqueryErr()
+	}
+	return nil
+}
+
+/**
+	Exists Query
+**/
+
+// Exists where condition: EXISTS (subquery)
+func Exists(sub *Subquery) QueryWhere {
+	return QueryWhere{query: "EXISTS " + sub.GetQuery(), args: sub.GetArgs(), err: sub.Err()}
+}
+
+// NotExists where condition: NOT EXISTS (subquery)
+func NotExists(sub *Subquery) QueryWhere {
+	return QueryWhere{query: "NOT EXISTS " + sub.GetQuery(), args: sub.GetArgs(), err: sub.Err()}
+}
+
+/**
+	Join a derived table
+**/
+
+// JoinSub joins a derived table: goje.JoinSub(goje.Left, sub, "totals", "totals.user_id = users.id")
+func JoinSub(joinType string, sub *Subquery, alias string, on string, args ...any) QueryJoin {
+	return QueryJoin{
+		on:       on,
+		joinType: joinType,
+		table:    sub.as(alias),
+		args:     append(append([]any{}, sub.GetArgs()...), args...),
+		err:      sub.Err(),
+	}
+}
+
+/**
+	From a derived table
+**/
+
+// QueryFrom a derived table that replaces the table of the select
+type QueryFrom struct {
+	sub   *Subquery
+	alias string
+}
+
+func (q QueryFrom) GetType() string {
+	return QueryTypeFrom
+}
+
+func (q QueryFrom) GetQuery() string {
+	return q.sub.as(q.alias)
+}
+
+func (q QueryFrom) GetArgs() []any {
+	return q.sub.GetArgs()
+}
+
+func (q QueryFrom) subqueryErr() error {
+	return q.sub.Err()
+}
+
+// FromSub selects from a derived table instead of the table, the Tablename of the builder is ignored
+func FromSub(sub *Subquery, alias string) QueryFrom {
+	return QueryFrom{sub: sub, alias: alias}
+}
+
+/**
+	Scalar column
+**/
+
+// QueryColumn a scalar subquery column, it's added after Columns of the select
+type QueryColumn struct {
+	sub   *Subquery
+	alias string
+}
+
+func (q QueryColumn) GetType() string {
+	return QueryTypeColumn
+}
+
+func (q QueryColumn) GetQuery() string {
+	return q.sub.as(q.alias)
+}
+
+func (q QueryColumn) GetArgs() []any {
+	return q.sub.GetArgs()
+}
+
+func (q QueryColumn) subqueryErr() error {
+	return q.sub.Err()
+}
+
+// ColumnSub selects the scalar subquery as the alias column
+func ColumnSub(sub *Subquery, alias string) QueryColumn {
+	return QueryColumn{sub: sub, alias: alias}
+}
+
+func (q QueryWhere) subqueryErr() error {
+	return q.err
+}
+
+func (q QueryJoin) subqueryErr() error {
+	return q.err
+}
+
+func (q QueryWhereIn) subqueryErr() error {
+	if sub, ok := subqueryOf(q.args); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+func (q QueryWhereNotIn) subqueryErr() error {
+	if sub, ok := subqueryOf(q.args); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+func (q QueryOR) subqueryErr() error {
+	var errs []error
+	for _, q := range q.queries {
+		errs = append(errs, queryErr(q))
+	}
+	return errors.Join(errs...)
+}
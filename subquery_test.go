@@ -0,0 +1,91 @@
+package goje
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubquery(t *testing.T) {
+	bigOrders := Sub("orders", []string{"user_id"}, Where("total > ?", 100))
+	tests := []struct {
+		name     string
+		table    string
+		columns  []string
+		queries  []QueryInterface
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:     "where in",
+			table:    "users",
+			columns:  []string{"id"},
+			queries:  []QueryInterface{Where("active = ?", true), WhereIn("id", bigOrders), Limit(10)},
+			want:     "SELECT `id`  FROM `users`  WHERE (active = ?) AND (id IN (SELECT `user_id`  FROM `orders`  WHERE (total > ?))) LIMIT ?",
+			wantArgs: []any{true, 100, Limit(10)},
+		},
+		{
+			name:     "not exists in or",
+			table:    "users",
+			columns:  []string{"id"},
+			queries:  []QueryInterface{OR(Where("id = ?", 1), NotExists(Sub("orders", []string{"*"}, Where("orders.user_id = users.id AND total > ?", 5))))},
+			want:     "SELECT `id`  FROM `users`  WHERE (id = ? OR NOT EXISTS (SELECT *  FROM `orders`  WHERE (orders.user_id = users.id AND total > ?)))",
+			wantArgs: []any{1, 5},
+		},
+		{
+			name:    "scalar column, derived table and join in positional order",
+			table:   "ignored",
+			columns: []string{"t.user_id"},
+			queries: []QueryInterface{
+				Where("t.total > ?", 4),
+				JoinSub(Left, Sub("payments", []string{"user_id", "SUM(amount) AS paid"}, Where("status = ?", "done"), GroupBy("user_id")), "p", "p.user_id = t.user_id"),
+				FromSub(Sub("orders", []string{"user_id", "SUM(total) AS total"}, Where("created_at > ?", "2024-01-01"), GroupBy("user_id")), "t"),
+				ColumnSub(Sub("users", []string{"name"}, Where("users.id = t.user_id AND kind = ?", "customer")), "name"),
+			},
+			want: "SELECT `t`.`user_id`,(SELECT `name`  FROM `users`  WHERE (users.id = t.user_id AND kind = ?)) AS `name`  FROM " +
+				"(SELECT `user_id`,SUM(total) AS total  FROM `orders`  WHERE (created_at > ?) GROUP BY `user_id`) AS `t`  " +
+				"LEFT JOIN (SELECT `user_id`,SUM(amount) AS paid  FROM `payments`  WHERE (status = ?) GROUP BY `user_id`) AS `p` ON p.user_id = t.user_id  WHERE (t.total > ?)",
+			wantArgs: []any{"customer", "2024-01-01", "done", 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := DialectSelectQueryBuilder(MySQLDialect{}, tt.table, tt.columns, tt.queries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("query\n got %s\nwant %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSubqueryPostgres(t *testing.T) {
+	got, args, err := DialectSelectQueryBuilder(PostgresDialect{}, "users", []string{"id"}, []QueryInterface{
+		Where("active = ?", true),
+		WhereIn("id", DialectSub(PostgresDialect{}, "orders", []string{"user_id"}, Where("total > ?", 100), Offset(5))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "id"  FROM "users"  WHERE (active = $1) AND (id IN (SELECT "user_id"  FROM "orders"  WHERE (total > $2) OFFSET $3))`
+	if got != want || len(args) != 3 {
+		t.Errorf("query\n got %s %v\nwant %s", got, args, want)
+	}
+}
+
+func TestSubqueryErr(t *testing.T) {
+	broken := Sub("orders", []string{"id"}, Where("total > ?"))
+	if broken.Err() == nil {
+		t.Fatal("Sub() didn't fail by mismatched binds")
+	}
+	for _, q := range []QueryInterface{WhereIn("id", broken), Exists(broken), OR(Where("1"), NotExists(broken)), JoinSub(Inner, broken, "o", "o.id = users.id"), FromSub(broken, "o"), ColumnSub(broken, "o")} {
+		if _, _, err := SelectQueryBuilder("users", []string{"id"}, []QueryInterface{q}); !errors.Is(err, broken.Err()) {
+			t.Errorf("%T error = %v, want %v", q, err, broken.Err())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package goje
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// TxFunc runs queries of a transaction by tx context
+type TxFunc func(tx *Context) error
+
+// WithTx runs fn in a transaction of the default database,
+// commits if fn returns nil, rollbacks if it returns an error or panics (the panic is re-raised after rollback)
+//
+//	err := goje.WithTx(ctx, nil, func(tx *goje.Context) error {
+//		_, err := tx.RawUpdate("users", map[string]any{"credits": 100}, goje.Where("id = ?", id))
+//		return err
+//	})
+func WithTx(ctx context.Context, options *sql.TxOptions, fn TxFunc) error {
+	return WithTxDB(ctx, DefatultDB, options, fn)
+}
+
+// WithTxDB runs fn in a transaction of the database connection, same as WithTx
+func WithTxDB(ctx context.Context, db *sql.DB, options *sql.TxOptions, fn TxFunc) error {
+	return MakeHandlerDB(ctx, db).WithTx(options, fn)
+}
+
+// Begin starts a transaction on the database of the context,
+// on a transactional context it makes a nested one by SAVEPOINT: its Commit releases the savepoint
+// and its Rollback undoes only queries after the savepoint, options are ignored for nested ones
+func (c *Context) Begin(options *sql.TxOptions) (*Context, error) {
+	ctx := c.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !c.Tx {
+		db, ok := c.DB.(*sql.DB)
+		if !ok {
+			return nil, ErrCantBeginTx
+		}
+		// the transaction is running (for Managed.Shutdown) until Commit/Rollback, its queries run even on shutdown
+		done, err := c.track()
+		if err != nil {
+			return nil, err
+		}
+		tx, err := db.BeginTx(ctx, options)
+		if err != nil {
+			done()
+			return nil, err
+		}
+		out := *c
+		out.Ctx, out.DB, out.Tx, out.Dialect = ctx, tx, true, c.GetDialect()
+		out.txDone = done
+		return &out, nil
+	}
+
+	tx, ok := c.DB.(*sql.Tx)
+	if !ok {
+		return nil, ErrTxIsntSet
+	}
+
+	nested := *c
+	nested.Ctx = ctx
+	nested.depth = c.depth + 1
+	nested.savepoint = fmt.Sprintf("goje_sp_%d", nested.depth)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+nested.savepoint); err != nil {
+		return nil, err
+	}
+	return &nested, nil
+}
+
+// WithTx runs fn in a transaction that begins by Begin, a nested one on a transactional context
+//
+//	err := tx.WithTx(nil, func(inner *goje.Context) error {
+//		// a failure here rollbacks to the savepoint, tx is still usable
+//	})
+func (c *Context) WithTx(options *sql.TxOptions, fn TxFunc) error {
+	tx, err := c.Begin(options)
+	if err != nil {
+		return err
+	}
+	return runTx(tx, fn)
+}
+
+// runTx runs fn and ends the tx by its result
+func runTx(tx *Context, fn TxFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollbackTx(tx)
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rbErr := rollbackTx(tx); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rollbackTx rollbacks the tx, a tx that is already rolled back (by a hook error) isn't an error
+func rollbackTx(tx *Context) error {
+	err := tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package goje
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithTx(t *testing.T) {
+	errFn := errors.New("fn failed")
+	errRollback := errors.New("connection lost")
+
+	tests := []struct {
+		name        string
+		fn          TxFunc
+		rollbackErr error
+		wantLog     []string
+		wantErrs    []error
+	}{
+		{
+			name: "commit",
+			fn: func(tx *Context) error {
+				_, err := tx.RawDelete("posts", []QueryInterface{Where("id = ?", 1)})
+				return err
+			},
+			wantLog: []string{"BEGIN", "DELETE FROM `posts`  WHERE (id = ?)", "COMMIT"},
+		},
+		{
+			name:     "rollback",
+			fn:       func(tx *Context) error { return errFn },
+			wantLog:  []string{"BEGIN", "ROLLBACK"},
+			wantErrs: []error{errFn},
+		},
+		{
+			name:        "rollback failed",
+			fn:          func(tx *Context) error { return errFn },
+			rollbackErr: errRollback,
+			wantLog:     []string{"BEGIN", "ROLLBACK"},
+			wantErrs:    []error{errFn, errRollback},
+		},
+		{
+			name: "rolled back by a hook",
+			fn: func(tx *Context) error {
+				_ = tx.Rollback()
+				return errFn
+			},
+			wantLog:  []string{"BEGIN", "ROLLBACK"},
+			wantErrs: []error{errFn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.rollbackErr = tt.rollbackErr
+
+			err := WithTxDB(context.Background(), db, nil, tt.fn)
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Fatalf("WithTxDB() error = %v", err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("WithTxDB() error = %v, want %v", err, want)
+				}
+			}
+			if got := fake.Log(); !reflect.DeepEqual(got, tt.wantLog) {
+				t.Errorf("WithTxDB() log = %q, want %q", got, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db, fake := newFakeDB(t)
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("WithTxDB() panic = %v, want boom", p)
+		}
+		if got, want := fake.Log(), []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("WithTxDB() log = %q, want %q", got, want)
+		}
+	}()
+
+	_ = WithTxDB(context.Background(), db, nil, func(tx *Context) error {
+		panic("boom")
+	})
+	t.Error("WithTxDB() didn't re-panic")
+}
+
+func TestNestedTx(t *testing.T) {
+	db, fake := newFakeDB(t)
+	errInner := errors.New("inner failed")
+
+	err := WithTxDB(context.Background(), db, nil, func(tx *Context) error {
+		if err := tx.WithTx(nil, func(inner *Context) error {
+			return inner.WithTx(nil, func(*Context) error { return nil })
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.WithTx(nil, func(*Context) error { return errInner }); !errors.Is(err, errInner) {
+			t.Errorf("WithTx() error = %v, want %v", err, errInner)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BEGIN",
+		"SAVEPOINT goje_sp_1",
+		"SAVEPOINT goje_sp_2",
+		"RELEASE SAVEPOINT goje_sp_2",
+		"RELEASE SAVEPOINT goje_sp_1",
+		"SAVEPOINT goje_sp_1",
+		"ROLLBACK TO SAVEPOINT goje_sp_1",
+		"COMMIT",
+	}
+	if got := fake.Log(); !reflect.DeepEqual(got, want) {
+		t.Errorf("nested WithTx() log = %q, want %q", got, want)
+	}
+
+	if _, err := (&Context{Ctx: context.Background(), DB: fakeQueryAble{}}).Begin(nil); !errors.Is(err, ErrCantBeginTx) {
+		t.Errorf("Begin() error = %v, want %v", err, ErrCantBeginTx)
+	}
+}
+
+// fakeQueryAble a QueryAble that isn't a *sql.DB
+type fakeQueryAble struct{ QueryAble }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sync/atomic"
 )
 
 type QueryAble interface {
@@ -19,9 +20,41 @@ type QueryAble interface {
 
 // Context and Database Handler struct
 type Context struct {
-	DB  QueryAble
-	Ctx context.Context
-	Tx  bool
+	DB      QueryAble
+	Ctx     context.Context
+	Tx      bool
+	Dialect Dialect
+
+	// savepoint of a nested transaction, Commit releases it and Rollback rollbacks to it
+	savepoint string
+	depth     int
+	// attempt of WithRetryTx that runs this tx
+	attempt int
+	// middlewares of queries, after DefaultMiddlewares
+	middlewares []Middleware
+	log         LogConfig
+	slowQueries *atomic.Uint64
+	dbName      string
+	cluster     *Cluster
+	queries     *queryTracker
+	// txDone ends counting the transaction as running, by Commit/Rollback
+	txDone func()
+}
+
+// GetDialect returns dialect of the context, DefaultDialect if it isn't set
+func (c *Context) GetDialect() Dialect {
+	if c.Dialect != nil {
+		return c.Dialect
+	}
+	return DefaultDialect
+}
+
+// Attempt returns the attempt of WithRetryTx that runs the transaction, 1 out of a retry
+func (c *Context) Attempt() int {
+	if c.attempt < 1 {
+		return 1
+	}
+	return c.attempt
 }
 
 func (c Context) Commit() error {
@@ -32,8 +65,15 @@ func (c Context) Commit() error {
 	if !ok {
 		return ErrTxIsntSet
 	}
+	if c.savepoint != "" {
+		_, err := tx.ExecContext(c.Ctx, "RELEASE SAVEPOINT "+c.savepoint)
+		return err
+	}
 
 	err := tx.Commit()
+	if c.txDone != nil {
+		c.txDone()
+	}
 	if err != nil {
 		return err
 	}
@@ -49,8 +89,15 @@ func (c Context) Rollback() error {
 	if !ok {
 		return ErrTxIsntSet
 	}
+	if c.savepoint != "" {
+		_, err := tx.ExecContext(c.Ctx, "ROLLBACK TO SAVEPOINT "+c.savepoint)
+		return err
+	}
 
 	err := tx.Rollback()
+	if c.txDone != nil {
+		c.txDone()
+	}
 	if err != nil {
 		return err
 	}
@@ -64,7 +111,14 @@ type Entity interface {
 	GetColumns() []string
 	GetCtx() *Context
 	GetParent() *Entity
-	// TODO: add GetOrCreate() error
+}
+
+// ForeignKey metadata of a foreign key constraint, generated entities expose them
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
 }
 
 var (
@@ -74,6 +128,22 @@ var (
 	ErrNoRowsForInsert     = errors.New("there isn't any row for insert into database")
 	ErrNoRowsColsForInsert = errors.New("cols should have at least one proprty for update")
 	ErrUnknownDBDriver     = errors.New("goje doesn't support this driver")
+	ErrNoDBPath            = errors.New("database file path dosen't set")
+	ErrNotAStruct          = errors.New("destination should be a struct or a pointer to struct")
+	ErrNoDBFields          = errors.New("struct dosen't have any field with `db` tag")
+	ErrNoPrimaryKey        = errors.New("entity dosen't have any primary key, tag it by `db:\"id,pk\"`")
+	ErrEntityIsntPointer   = errors.New("entity should be a pointer to struct")
+	ErrUnknownColumn       = errors.New("entity dosen't have the column")
+	ErrNotAnEntity         = errors.New("it isn't an entity")
+	ErrUnknownRelation     = errors.New("entity dosen't have the relation")
+	ErrGetOrCreateConflict = errors.New("entity neither could be fetched nor inserted, check the unique key of match columns")
 	ErrIsntATx             = errors.New("it isn't a transactional context")
 	ErrTxIsntSet           = errors.New("there is not any transaction context")
+	ErrCantBeginTx         = errors.New("database of the context can't begin a transaction")
+	ErrUnknownBalance      = errors.New("unknown balance strategy of replicas")
+	ErrUnknownDB           = errors.New("database isn't registered")
+	ErrDBAlreadyRegistered = errors.New("database is already registered")
+	ErrShuttingDown        = errors.New("database is shutting down")
+	ErrNoCompoundSelects   = errors.New("compound query should have at least two selects")
+	ErrQueryDidntRun       = errors.New("a middleware returned without an error nor running the query")
 )
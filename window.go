@@ -0,0 +1,198 @@
+package goje
+
+import (
+	"strconv"
+	"strings"
+)
+
+const QueryTypeWindow = "window"
+
+// Frame bounds of Window.Rows and Window.Range
+const (
+	UnboundedPreceding = "UNBOUNDED PRECEDING"
+	UnboundedFollowing = "UNBOUNDED FOLLOWING"
+	CurrentRow         = "CURRENT ROW"
+)
+
+// Preceding frame bound: n PRECEDING
+func Preceding(n int) string {
+	return strconv.Itoa(n) + " PRECEDING"
+}
+
+// Following frame bound: n FOLLOWING
+func Following(n int) string {
+	return strconv.Itoa(n) + " FOLLOWING"
+}
+
+/**
+	Window
+**/
+
+// Window specification of OVER (...), methods return a copy
+//
+//	goje.RowNumber(goje.Over().PartitionBy("user_id").OrderBy("created_at DESC"), "rn")
+//	goje.Aggregate("SUM", "amount", goje.Over().OrderBy("id").Rows(goje.UnboundedPreceding, goje.CurrentRow), "running_total")
+type Window struct {
+	name      string
+	partition []string
+	order     []string
+	frame     string
+}
+
+// Over an empty window: OVER ()
+func Over() Window {
+	return Window{}
+}
+
+// OverWindow refers a window of the WINDOW clause (WindowAs): OVER `name`
+func OverWindow(name string) Window {
+	return Window{name: name}
+}
+
+// PartitionBy columns of the window
+func (w Window) PartitionBy(columns ...string) Window {
+	w.partition = append(w.partition[:len(w.partition):len(w.partition)], columns...)
+	return w
+}
+
+// OrderBy columns of the window, with ASC/DESC: "created_at DESC"
+func (w Window) OrderBy(columns ...string) Window {
+	w.order = append(w.order[:len(w.order):len(w.order)], columns...)
+	return w
+}
+
+// Rows frame of the window: ROWS BETWEEN start AND end
+func (w Window) Rows(start, end string) Window {
+	w.frame = "ROWS BETWEEN " + start + " AND " + end
+	return w
+}
+
+// Range frame of the window: RANGE BETWEEN start AND end
+func (w Window) Range(start, end string) Window {
+	w.frame = "RANGE BETWEEN " + start + " AND " + end
+	return w
+}
+
+// spec returns the window specification without parentheses
+func (w Window) spec() string {
+	var parts []string
+	if w.name != "" {
+		parts = append(parts, qouteColumn(w.name))
+	}
+	if len(w.partition) > 0 {
+		columns := make([]string, len(w.partition))
+		for i := range w.partition {
+			columns[i] = qouteColumn(w.partition[i])
+		}
+		parts = append(parts, "PARTITION BY "+strings.Join(columns, ","))
+	}
+	if len(w.order) > 0 {
+		columns := make([]string, len(w.order))
+		for i := range w.order {
+			columns[i] = qouteOrder(w.order[i])
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(columns, ","))
+	}
+	if w.frame != "" {
+		parts = append(parts, w.frame)
+	}
+	return strings.Join(parts, " ")
+}
+
+// String returns OVER clause of the window
+func (w Window) String() string {
+	if w.name != "" && len(w.partition) == 0 && len(w.order) == 0 && w.frame == "" {
+		return "OVER " + qouteColumn(w.name)
+	}
+	return "OVER (" + w.spec() + ")"
+}
+
+// qouteOrder qoutes column of an order item, keeps ASC/DESC
+func qouteOrder(item string) string {
+	item = strings.TrimSpace(item)
+	if i := strings.LastIndexByte(item, ' '); i > 0 {
+		switch strings.ToUpper(item[i+1:]) {
+		case "ASC", "DESC":
+			return qouteColumn(item[:i]) + item[i:]
+		}
+	}
+	return qouteColumn(item)
+}
+
+/**
+	Window function columns
+**/
+
+// windowColumn renders fn OVER (...) AS `alias`, alias is optional
+func windowColumn(fn string, over Window, alias string) string {
+	column := fn + " " + over.String()
+	if alias != "" {
+		column += " AS " + qouteColumn(alias)
+	}
+	return column
+}
+
+// RowNumber column: ROW_NUMBER() OVER (...) AS `alias`
+func RowNumber(over Window, alias string) string {
+	return windowColumn("ROW_NUMBER()", over, alias)
+}
+
+// Rank column: RANK() OVER (...) AS `alias`
+func Rank(over Window, alias string) string {
+	return windowColumn("RANK()", over, alias)
+}
+
+// DenseRank column: DENSE_RANK() OVER (...) AS `alias`
+func DenseRank(over Window, alias string) string {
+	return windowColumn("DENSE_RANK()", over, alias)
+}
+
+// Lag column: LAG(`column`, offset) OVER (...) AS `alias`, offset 0 is the default 1
+func Lag(column string, offset int, over Window, alias string) string {
+	return windowColumn(offsetFunc("LAG", column, offset), over, alias)
+}
+
+// Lead column: LEAD(`column`, offset) OVER (...) AS `alias`, offset 0 is the default 1
+func Lead(column string, offset int, over Window, alias string) string {
+	return windowColumn(offsetFunc("LEAD", column, offset), over, alias)
+}
+
+// Aggregate column of an aggregate function over the window: SUM(`amount`) OVER (...) AS `alias`
+func Aggregate(fn, column string, over Window, alias string) string {
+	return windowColumn(strings.ToUpper(fn)+"("+qouteColumn(column)+")", over, alias)
+}
+
+func offsetFunc(fn, column string, offset int) string {
+	if offset <= 0 {
+		return fn + "(" + qouteColumn(column) + ")"
+	}
+	return fn + "(" + qouteColumn(column) + ", " + strconv.Itoa(offset) + ")"
+}
+
+/**
+	Named window
+**/
+
+// QueryWindow a named window of the WINDOW clause, columns refer it by OverWindow
+type QueryWindow struct {
+	name   string
+	window Window
+}
+
+func (q QueryWindow) GetType() string {
+	return QueryTypeWindow
+}
+
+// GetQuery returns `name` AS (...)
+func (q QueryWindow) GetQuery() string {
+	return qouteColumn(q.name) + " AS (" + q.window.spec() + ")"
+}
+
+func (q QueryWindow) GetArgs() []any {
+	return nil
+}
+
+// WindowAs names the window: WINDOW `name` AS (...)
+func WindowAs(name string, window Window) QueryWindow {
+	return QueryWindow{name: name, window: window}
+}
@@ -0,0 +1,81 @@
+package goje
+
+import "testing"
+
+func TestWindowColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		want   string
+	}{
+		{
+			name:   "row number",
+			column: RowNumber(Over().PartitionBy("user_id").OrderBy("created_at DESC", "id"), "rn"),
+			want:   "ROW_NUMBER() OVER (PARTITION BY `user_id` ORDER BY `created_at` DESC,`id`) AS `rn`",
+		},
+		{
+			name:   "rank",
+			column: Rank(Over().OrderBy("score desc"), "rank"),
+			want:   "RANK() OVER (ORDER BY `score` desc) AS `rank`",
+		},
+		{
+			name:   "dense rank without alias",
+			column: DenseRank(Over().PartitionBy("t.team_id").OrderBy("t.score DESC"), ""),
+			want:   "DENSE_RANK() OVER (PARTITION BY `t`.`team_id` ORDER BY `t`.`score` DESC)",
+		},
+		{
+			name:   "lag and lead",
+			column: Lag("price", 0, Over().OrderBy("day"), "prev") + "," + Lead("price", 2, Over().OrderBy("day"), "next"),
+			want:   "LAG(`price`) OVER (ORDER BY `day`) AS `prev`,LEAD(`price`, 2) OVER (ORDER BY `day`) AS `next`",
+		},
+		{
+			name:   "running total",
+			column: Aggregate("sum", "amount", Over().PartitionBy("account_id").OrderBy("id").Rows(UnboundedPreceding, CurrentRow), "balance"),
+			want:   "SUM(`amount`) OVER (PARTITION BY `account_id` ORDER BY `id` ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS `balance`",
+		},
+		{
+			name:   "moving average",
+			column: Aggregate("AVG", "price", Over().OrderBy("day").Rows(Preceding(6), CurrentRow), "avg7"),
+			want:   "AVG(`price`) OVER (ORDER BY `day` ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS `avg7`",
+		},
+		{
+			name:   "named window",
+			column: Aggregate("COUNT", "*", OverWindow("w"), "n") + "," + RowNumber(OverWindow("w").Range(UnboundedPreceding, Following(1)), ""),
+			want:   "COUNT(*) OVER `w` AS `n`,ROW_NUMBER() OVER (`w` RANGE BETWEEN UNBOUNDED PRECEDING AND 1 FOLLOWING)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.column != tt.want {
+				t.Errorf("column\n got %s\nwant %s", tt.column, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowClause(t *testing.T) {
+	base := Over().PartitionBy("user_id")
+	byDate := base.OrderBy("created_at")
+	if len(base.order) != 0 {
+		t.Fatal("OrderBy() changed the base window")
+	}
+
+	got, args, err := DialectSelectQueryBuilder(PostgresDialect{}, "orders", []string{
+		"id",
+		RowNumber(OverWindow("w"), "rn"),
+		Aggregate("SUM", "total", OverWindow("w"), "running"),
+	}, []QueryInterface{
+		Where("status = ?", "paid"),
+		WindowAs("w", byDate),
+		Order("id"),
+		Limit(10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "id",ROW_NUMBER() OVER "w" AS "rn",SUM("total") OVER "w" AS "running"  FROM "orders"  WHERE (status = $1) ` +
+		`WINDOW "w" AS (PARTITION BY "user_id" ORDER BY "created_at") ORDER BY id LIMIT $2`
+	if got != want || len(args) != 2 {
+		t.Errorf("query\n got %s %v\nwant %s", got, args, want)
+	}
+}